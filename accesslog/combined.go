@@ -0,0 +1,85 @@
+package accesslog
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// combinedTimeFormat is the time format of the Common Log Format
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// combinedWriter writes the Apache Combined Log Format lines to w, one Write per line.
+type combinedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (cw *combinedWriter) write(r *http.Request, start time.Time, status int, size int64) {
+	line := combinedLine(r, start, status, size)
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	_, _ = io.WriteString(cw.w, line)
+}
+
+// combinedLine formats the request as a line of the Apache Combined Log Format:
+//
+//	host ident user [time] "request" status size "referer" "user agent"
+func combinedLine(r *http.Request, start time.Time, status int, size int64) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = combinedEscape(u)
+	}
+	bytes := "-"
+	if size > 0 {
+		bytes = strconv.FormatInt(size, 10)
+	}
+
+	var b strings.Builder
+	b.WriteString(combinedValue(host))
+	b.WriteString(" - ")
+	b.WriteString(user)
+	b.WriteString(" [")
+	b.WriteString(start.Format(combinedTimeFormat))
+	b.WriteString(`] "`)
+	b.WriteString(combinedEscape(r.Method + " " + r.RequestURI + " " + r.Proto))
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(status))
+	b.WriteByte(' ')
+	b.WriteString(bytes)
+	b.WriteString(` "`)
+	b.WriteString(combinedValue(combinedEscape(r.Referer())))
+	b.WriteString(`" "`)
+	b.WriteString(combinedValue(combinedEscape(r.UserAgent())))
+	b.WriteString("\"\n")
+	return b.String()
+}
+
+// combinedValue returns "-" for an empty value like Apache does.
+func combinedValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// combinedEscape escapes the quotes, backslashes and control characters, so a value can not break the line format.
+func combinedEscape(s string) string {
+	if !strings.ContainsAny(s, "\"\\") && strings.IndexFunc(s, isControl) < 0 {
+		return s
+	}
+	q := strconv.Quote(s)
+	return q[1 : len(q)-1]
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
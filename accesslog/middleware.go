@@ -2,35 +2,289 @@
 package accesslog
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"time"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 	"github.com/go-ozzo/ozzo-routing/v2/access"
+	"go.uber.org/zap"
 
 	"github.com/minipkg/log"
 )
 
+// Config for the access log middleware
+type Config struct {
+	// LatencyBuckets are the ascending upper bounds of the latency buckets. If set, every access log message
+	// gets a "latency_bucket" field like "100-250ms", or "+Inf" for latencies beyond the last bound.
+	LatencyBuckets []time.Duration
+	// ResponseHeaders are the names of the response headers added as "resp_<name>" fields if present.
+	ResponseHeaders []string
+	// RequestHeaders are the names of the request headers added as "req_<canonical name>" fields if present.
+	// The values of the sensitive headers, like Authorization and Cookie, are logged as log.Redacted.
+	RequestHeaders []string
+	// Route extracts the route pattern matched for the request, like "/users/{id}", which is added as the "route"
+	// field to the access log and the loggers derived via With from the request context. E.g. for chi it may return
+	// chi.RouteContext(r.Context()).RoutePattern(), for gorilla/mux the path template of mux.CurrentRoute(r).
+	// It is called before the request is handled, and once more after that if it returned "".
+	Route func(r *http.Request) string
+	// IdempotencyHashBytes makes the access log carry the hex SHA-256 hash of the first IdempotencyHashBytes bytes
+	// of the request body as the "idempotency_hash" field, no hash if zero. The handler still reads the whole body.
+	IdempotencyHashBytes int64
+	// QuietPaths are the URL paths, like "/healthz" and "/readyz", whose requests are logged at DEBUG level
+	// instead of INFO, so the probes do not flood the access log. See also Quiet.
+	QuietPaths []string
+	// PanicResponse writes the response to a request whose handler panicked, after the panic is logged.
+	// A plain "Internal Server Error" with the 500 status is written if nil.
+	PanicResponse func(w http.ResponseWriter, r *http.Request, recovered interface{})
+	// AccessLogCombined receives a line in the Apache Combined Log Format for every request, besides the structured
+	// access log message, for the legacy log analysis tools like GoAccess. The lines are not written if nil.
+	AccessLogCombined io.Writer
+	// TLS adds the TLSFields of the request to the access log messages.
+	TLS bool
+}
+
+type quietKey struct{}
+
+// Quiet makes the access log message of the request being handled in ctx be logged at DEBUG level instead of INFO.
+// It has no effect unless ctx is the context of a request passed through the middleware.
+func Quiet(ctx context.Context) {
+	if quiet, ok := ctx.Value(quietKey{}).(*bool); ok {
+		*quiet = true
+	}
+}
+
 // Handler returns a middleware that records an access log message for every HTTP request being processed.
 func Handler(logger log.Logger) routing.Handler {
+	return HandlerWithConfig(logger, Config{})
+}
+
+// HandlerWithConfig returns a middleware like Handler which is configured by conf.
+func HandlerWithConfig(logger log.Logger, conf Config) routing.Handler {
+	var combined *combinedWriter
+	if conf.AccessLogCombined != nil {
+		combined = &combinedWriter{w: conf.AccessLogCombined}
+	}
+
 	return func(c *routing.Context) error {
 		start := time.Now()
 
 		rw := &access.LogResponseWriter{ResponseWriter: c.Response, Status: http.StatusOK}
 		c.Response = rw
 
+		var hw *headerWriter
+		if len(conf.ResponseHeaders) > 0 {
+			hw = &headerWriter{ResponseWriter: rw, names: conf.ResponseHeaders}
+			c.Response = hw
+		}
+
+		var bodyHash string
+		if conf.IdempotencyHashBytes > 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			bodyHash = hashBody(c.Request, conf.IdempotencyHashBytes)
+		}
+
 		// associate request ID and session ID with the request context
 		// so that they can be added to the log messages
 		ctx := c.Request.Context()
+		quiet := isQuietPath(c.Request.URL.Path, conf.QuietPaths)
+		ctx = context.WithValue(ctx, quietKey{}, &quiet)
 		ctx = log.WithRequest(ctx, c.Request)
+		if conf.Route != nil {
+			if route := conf.Route(c.Request); route != "" {
+				ctx = log.WithRoute(ctx, route)
+			}
+		}
 		c.Request = c.Request.WithContext(ctx)
 
-		err := c.Next()
+		// echo the IDs to the client before the handler runs, so the handler may still replace them
+		setHeader(c.Response.Header(), "X-Request-ID", log.RequestID(ctx))
+		setHeader(c.Response.Header(), "X-Correlation-ID", log.CorrelationID(ctx))
+
+		err := next(c, logger, conf)
 
 		// generate an access log message
-		logger.With(ctx, "duration", time.Since(start).Milliseconds(), "status", rw.Status).
-			Infof("%s %s %s %d %d", c.Request.Method, c.Request.URL.Path, c.Request.Proto, rw.Status, rw.BytesWritten)
+		latency := time.Since(start)
+		args := []interface{}{
+			"duration", latency.Milliseconds(),
+			// zap encodes floats without exponent, so sub-millisecond latencies read like 0.473
+			"latency_ms", float64(latency.Microseconds()) / 1000,
+			"latency_ns", latency.Nanoseconds(),
+			"status", rw.Status,
+		}
+		// routers like chi know the full pattern only after the request is routed
+		if conf.Route != nil && log.Route(ctx) == "" {
+			if route := conf.Route(c.Request); route != "" {
+				args = append(args, "route", route)
+			}
+		}
+		if bodyHash != "" {
+			args = append(args, "idempotency_hash", bodyHash)
+		}
+		if len(conf.LatencyBuckets) > 0 {
+			args = append(args, "latency_bucket", latencyBucket(latency, conf.LatencyBuckets))
+		}
+		if hw != nil {
+			args = append(args, hw.fields()...)
+		}
+		args = append(args, requestHeaderFields(c.Request.Header, conf.RequestHeaders)...)
+		if conf.TLS {
+			args = append(args, TLSFields(c.Request)...)
+		}
+		format, values := "%s %s %s %d %d", []interface{}{c.Request.Method, c.Request.URL.Path, c.Request.Proto, rw.Status, rw.BytesWritten}
+		if quiet {
+			logger.With(ctx, args...).Debugf(format, values...)
+		} else {
+			logger.With(ctx, args...).Infof(format, values...)
+		}
+		if combined != nil {
+			combined.write(c.Request, start, rw.Status, rw.BytesWritten)
+		}
 
 		return err
 	}
 }
+
+// next calls the next handlers recovering their panics. A recovered panic is logged at ERROR level with the panic
+// value, the stack trace and the request, and answered by conf.PanicResponse.
+func next(c *routing.Context, logger log.Logger, conf Config) (err error) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		if p == http.ErrAbortHandler {
+			// the handler aborted the response on purpose, see http.ErrAbortHandler
+			panic(p)
+		}
+
+		logger.With(c.Request.Context()).Errorw("Panic recovered",
+			"panic", p,
+			zap.Stack("stacktrace"),
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+		)
+		if conf.PanicResponse != nil {
+			conf.PanicResponse(c.Response, c.Request, p)
+		} else {
+			http.Error(c.Response, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		// skip the handlers following the panicking one
+		c.Abort()
+		err = nil
+	}()
+	return c.Next()
+}
+
+func isQuietPath(path string, quietPaths []string) bool {
+	for _, p := range quietPaths {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// setHeader sets the header to value unless the value is empty or the header is already set.
+func setHeader(h http.Header, name, value string) {
+	if value != "" && h.Get(name) == "" {
+		h.Set(name, value)
+	}
+}
+
+// hashBody returns the hex SHA-256 hash of at most max leading bytes of the request body, or "" if the body
+// can not be read. The bytes read are put back in front of the rest of the body, so the handler reads the body
+// unchanged and gets the read error itself.
+func hashBody(r *http.Request, max int64) string {
+	head, err := ioutil.ReadAll(io.LimitReader(r.Body, max))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(head)
+	return hex.EncodeToString(sum[:])
+}
+
+// latencyBucket returns the label of the first bucket whose upper bound is not less than latency.
+func latencyBucket(latency time.Duration, buckets []time.Duration) string {
+	var lower time.Duration
+	for _, upper := range buckets {
+		if latency <= upper {
+			return fmt.Sprintf("%d-%dms", lower.Milliseconds(), upper.Milliseconds())
+		}
+		lower = upper
+	}
+	return "+Inf"
+}
+
+// sensitiveHeaders are the canonical names of the request headers whose values are never logged
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+// requestHeaderFields returns the named headers as "req_<canonical name>" fields omitting the missing ones.
+func requestHeaderFields(h http.Header, names []string) []interface{} {
+	var fields []interface{}
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		value := h.Get(name)
+		if value == "" {
+			continue
+		}
+		if sensitiveHeaders[name] {
+			value = log.Redacted
+		}
+		fields = append(fields, "req_"+name, value)
+	}
+	return fields
+}
+
+// headerWriter captures the values of the named headers at the time the response header is written.
+type headerWriter struct {
+	http.ResponseWriter
+	names  []string
+	values []string
+}
+
+func (w *headerWriter) WriteHeader(status int) {
+	w.capture()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *headerWriter) Write(p []byte) (int, error) {
+	w.capture()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *headerWriter) capture() {
+	if w.values != nil {
+		return
+	}
+	w.values = make([]string, len(w.names))
+	for i, name := range w.names {
+		w.values[i] = w.Header().Get(name)
+	}
+}
+
+// fields returns the captured headers as "resp_<name>" fields omitting the missing ones.
+func (w *headerWriter) fields() []interface{} {
+	w.capture()
+	fields := make([]interface{}, 0, 2*len(w.names))
+	for i, name := range w.names {
+		if w.values[i] != "" {
+			fields = append(fields, "resp_"+name, w.values[i])
+		}
+	}
+	return fields
+}
@@ -0,0 +1,326 @@
+package accesslog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/minipkg/log"
+	"github.com/minipkg/log/logtest"
+)
+
+func TestAccessLogCombined(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	var combined bytes.Buffer
+	h := HandlerWithConfig(logger, Config{AccessLogCombined: &combined})
+
+	req := httptest.NewRequest("GET", "/users?id=1", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", `curl "7.68"`)
+	res := httptest.NewRecorder()
+	c := routing.NewContext(res, req, h, func(c *routing.Context) error {
+		c.Response.WriteHeader(http.StatusCreated)
+		_, err := c.Response.Write([]byte("hello"))
+		return err
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	ent := <-entries
+	if ent.Level != zapcore.InfoLevel || ent.ContextMap()["status"] != int64(http.StatusCreated) {
+		t.Errorf("access log entry = %v %v, want INFO with status 201", ent.Level, ent.ContextMap())
+	}
+
+	line := combined.String()
+	if !strings.HasPrefix(line, "192.0.2.1 - - [") {
+		t.Errorf("combined line %q does not start with the host", line)
+	}
+	want := `] "GET /users?id=1 HTTP/1.1" 201 5 "-" "curl \"7.68\""` + "\n"
+	if !strings.HasSuffix(line, want) {
+		t.Errorf("combined line = %q, want suffix %q", line, want)
+	}
+}
+
+func TestAccessLogCombinedNil(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{})
+	c := routing.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), h)
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if ent := <-entries; ent.Level != zapcore.InfoLevel {
+		t.Errorf("access log level = %v, want INFO", ent.Level)
+	}
+}
+
+func TestLatencyBucket(t *testing.T) {
+	buckets := []time.Duration{100 * time.Millisecond, 250 * time.Millisecond}
+	for _, tt := range []struct {
+		latency time.Duration
+		want    string
+	}{
+		{50 * time.Millisecond, "0-100ms"},
+		{100 * time.Millisecond, "0-100ms"},
+		{101 * time.Millisecond, "100-250ms"},
+		{time.Second, "+Inf"},
+	} {
+		if got := latencyBucket(tt.latency, buckets); got != tt.want {
+			t.Errorf("latencyBucket(%v) = %q, want %q", tt.latency, got, tt.want)
+		}
+	}
+}
+
+func TestLatencyBucketField(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{LatencyBuckets: []time.Duration{time.Hour}})
+	c := routing.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), h)
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if got := (<-entries).ContextMap()["latency_bucket"]; got != "0-3600000ms" {
+		t.Errorf("latency_bucket = %v, want 0-3600000ms", got)
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{ResponseHeaders: []string{"Content-Type", "X-Cache"}})
+	c := routing.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), h, func(c *routing.Context) error {
+		c.Response.Header().Set("Content-Type", "text/plain")
+		c.Response.WriteHeader(http.StatusOK)
+		// set after the header is written, so not sent to the client
+		c.Response.Header().Set("X-Cache", "HIT")
+		return nil
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := (<-entries).ContextMap()
+	if fields["resp_Content-Type"] != "text/plain" {
+		t.Errorf("resp_Content-Type = %v, want text/plain", fields["resp_Content-Type"])
+	}
+	if v, ok := fields["resp_X-Cache"]; ok {
+		t.Errorf("resp_X-Cache = %v logged though set after the header was written", v)
+	}
+}
+
+func TestEchoIDs(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	res := httptest.NewRecorder()
+	c := routing.NewContext(res, req, Handler(logger))
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+	<-entries
+
+	if got := res.Header().Get("X-Request-ID"); got != "req-1" {
+		t.Errorf("X-Request-ID = %q, want req-1", got)
+	}
+	if got := res.Header().Get("X-Correlation-ID"); got != "corr-1" {
+		t.Errorf("X-Correlation-ID = %q, want corr-1", got)
+	}
+}
+
+func TestEchoGeneratedID(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	res := httptest.NewRecorder()
+	c := routing.NewContext(res, httptest.NewRequest("GET", "/", nil), Handler(logger))
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+	<-entries
+
+	if res.Header().Get("X-Request-ID") == "" {
+		t.Error("the generated request ID is not echoed")
+	}
+	if got := res.Header().Get("X-Correlation-ID"); got != "" {
+		t.Errorf("X-Correlation-ID = %q, want none", got)
+	}
+}
+
+func TestLatencyFields(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	c := routing.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), Handler(logger), func(c *routing.Context) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := (<-entries).ContextMap()
+	ms, _ := fields["latency_ms"].(float64)
+	ns, _ := fields["latency_ns"].(int64)
+	if ms < 2 || ns < int64(2*time.Millisecond) {
+		t.Errorf("latency_ms = %v, latency_ns = %v, want at least 2ms", fields["latency_ms"], fields["latency_ns"])
+	}
+	if got := float64(ns/int64(time.Microsecond)) / 1000; got != ms {
+		t.Errorf("latency_ms = %v, want %v with microsecond precision", ms, got)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	var route string
+	h := HandlerWithConfig(logger, Config{Route: func(*http.Request) string { return route }})
+	c := routing.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil), h, func(c *routing.Context) error {
+		logger.With(c.Request.Context()).Info("handler")
+		// the route is known only after routing
+		route = "/users/{id}"
+		return nil
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fields := (<-entries).ContextMap(); fields["route"] != nil {
+		t.Errorf("handler entry route = %v, want none before routing", fields["route"])
+	}
+	if fields := (<-entries).ContextMap(); fields["route"] != "/users/{id}" {
+		t.Errorf("access log route = %v, want /users/{id}", fields["route"])
+	}
+}
+
+func TestRouteInContext(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{Route: func(*http.Request) string { return "/users/{id}" }})
+	c := routing.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil), h, func(c *routing.Context) error {
+		logger.With(c.Request.Context()).Info("handler")
+		return nil
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if fields := (<-entries).ContextMap(); fields["route"] != "/users/{id}" {
+			t.Errorf("entry %d route = %v, want /users/{id}", i, fields["route"])
+		}
+	}
+}
+
+func TestIdempotencyHash(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{IdempotencyHashBytes: 5})
+	var body []byte
+	req := httptest.NewRequest("POST", "/", strings.NewReader("hello world"))
+	c := routing.NewContext(httptest.NewRecorder(), req, h, func(c *routing.Context) error {
+		var err error
+		body, err = ioutil.ReadAll(c.Request.Body)
+		return err
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != "hello world" {
+		t.Errorf("the handler read %q, want the whole body", body)
+	}
+	sum := sha256.Sum256([]byte("hello"))
+	if got := (<-entries).ContextMap()["idempotency_hash"]; got != hex.EncodeToString(sum[:]) {
+		t.Errorf("idempotency_hash = %v, want the hash of the first 5 bytes", got)
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{QuietPaths: []string{"/healthz"}})
+	for _, tt := range []struct {
+		path  string
+		quiet bool
+		want  zapcore.Level
+	}{
+		{"/healthz", false, zapcore.DebugLevel},
+		{"/users", false, zapcore.InfoLevel},
+		{"/users", true, zapcore.DebugLevel},
+	} {
+		quiet := tt.quiet
+		c := routing.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil), h, func(c *routing.Context) error {
+			if quiet {
+				Quiet(c.Request.Context())
+			}
+			return nil
+		})
+		if err := c.Next(); err != nil {
+			t.Fatal(err)
+		}
+		if got := (<-entries).Level; got != tt.want {
+			t.Errorf("%s (quiet %v) logged at %v, want %v", tt.path, tt.quiet, got, tt.want)
+		}
+	}
+}
+
+func TestPanicRecovered(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	res := httptest.NewRecorder()
+	c := routing.NewContext(res, httptest.NewRequest("GET", "/boom", nil), Handler(logger), func(c *routing.Context) error {
+		panic("boom")
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	panicEntry := <-entries
+	if panicEntry.Level != zapcore.ErrorLevel || panicEntry.ContextMap()["panic"] != "boom" || panicEntry.ContextMap()["path"] != "/boom" {
+		t.Errorf("panic entry = %v %v, want an ERROR entry with the panic value and the path", panicEntry.Level, panicEntry.ContextMap())
+	}
+	if st, _ := panicEntry.ContextMap()["stacktrace"].(string); st == "" {
+		t.Error("the panic entry has no stack trace")
+	}
+	if status := (<-entries).ContextMap()["status"]; status != int64(http.StatusInternalServerError) || res.Code != http.StatusInternalServerError {
+		t.Errorf("status = %v, response %d, want 500", status, res.Code)
+	}
+}
+
+func TestPanicResponse(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{PanicResponse: func(w http.ResponseWriter, r *http.Request, recovered interface{}) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}})
+	res := httptest.NewRecorder()
+	c := routing.NewContext(res, httptest.NewRequest("GET", "/", nil), h, func(c *routing.Context) error {
+		panic("boom")
+	})
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+	<-entries
+	<-entries
+	if res.Code != http.StatusServiceUnavailable {
+		t.Errorf("response %d, want the custom 503", res.Code)
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	logger, entries := logtest.NewChannelLogger(10)
+	h := HandlerWithConfig(logger, Config{RequestHeaders: []string{"user-agent", "Authorization", "X-Missing"}})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", "curl")
+	req.Header.Set("Authorization", "Bearer secret")
+	c := routing.NewContext(httptest.NewRecorder(), req, h)
+	if err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := (<-entries).ContextMap()
+	if fields["req_User-Agent"] != "curl" || fields["req_Authorization"] != log.Redacted {
+		t.Errorf("fields = %v, want the user agent and the redacted authorization", fields)
+	}
+	if v, ok := fields["req_X-Missing"]; ok {
+		t.Errorf("req_X-Missing = %v logged though missing", v)
+	}
+}
@@ -0,0 +1,34 @@
+package accesslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// TLSFields returns the "tls_version", "tls_cipher" and, if the client presented a certificate, "client_cn"
+// (the common name of its subject) fields of the TLS connection of the request, or nil if it was not made over TLS.
+func TLSFields(r *http.Request) []interface{} {
+	if r.TLS == nil {
+		return nil
+	}
+	version, ok := tlsVersions[r.TLS.Version]
+	if !ok {
+		version = fmt.Sprintf("0x%04x", r.TLS.Version)
+	}
+	fields := []interface{}{
+		"tls_version", version,
+		"tls_cipher", tls.CipherSuiteName(r.TLS.CipherSuite),
+	}
+	if len(r.TLS.PeerCertificates) > 0 {
+		fields = append(fields, "client_cn", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}
+	return fields
+}
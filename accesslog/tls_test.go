@@ -0,0 +1,36 @@
+package accesslog
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTLSFields(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	if fields := TLSFields(req); fields != nil {
+		t.Errorf("TLSFields of a plain request = %v, want nil", fields)
+	}
+
+	req.TLS = &tls.ConnectionState{
+		Version:          tls.VersionTLS13,
+		CipherSuite:      tls.TLS_AES_128_GCM_SHA256,
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "client.example.com"}}},
+	}
+	want := []interface{}{
+		"tls_version", "TLS 1.3",
+		"tls_cipher", "TLS_AES_128_GCM_SHA256",
+		"client_cn", "client.example.com",
+	}
+	if fields := TLSFields(req); !reflect.DeepEqual(fields, want) {
+		t.Errorf("TLSFields = %v, want %v", fields, want)
+	}
+
+	req.TLS = &tls.ConnectionState{Version: 0x0305}
+	if fields := TLSFields(req); fields[1] != "0x0305" {
+		t.Errorf("tls_version = %v, want the hex version", fields[1])
+	}
+}
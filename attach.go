@@ -0,0 +1,83 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// contextError is an error carrying the request and correlation IDs of the context it was returned in.
+type contextError struct {
+	err           error
+	requestID     string
+	correlationID string
+}
+
+// AttachContext returns err carrying the request and correlation IDs of ctx, which WithError adds to the entries
+// logged with the error even if they are logged far away, without the context. The error message and the causes
+// of err are unchanged. err is returned as it is if it is nil or ctx carries no IDs.
+func AttachContext(ctx context.Context, err error) error {
+	if err == nil || ctx == nil {
+		return err
+	}
+	requestID, correlationID := RequestID(ctx), CorrelationID(ctx)
+	if requestID == "" && correlationID == "" {
+		return err
+	}
+	return &contextError{err: err, requestID: requestID, correlationID: correlationID}
+}
+
+func (e *contextError) Error() string { return e.err.Error() }
+
+// Cause returns the wrapped error for errors.Cause of github.com/pkg/errors.
+func (e *contextError) Cause() error { return e.err }
+
+// Unwrap returns the wrapped error for errors.Is and errors.As.
+func (e *contextError) Unwrap() error { return e.err }
+
+// Format prints the wrapped error, so "%+v" keeps its stack trace.
+func (e *contextError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", e.err)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// attachedFields returns the fields of the IDs attached to err by AttachContext, except the IDs the logger
+// already carries.
+func (l *logger) attachedFields(err error) []interface{} {
+	var ce *contextError
+	if !errors.As(err, &ce) {
+		return nil
+	}
+
+	var fields []zap.Field
+	if ce.requestID != "" && ce.requestID != l.requestID {
+		fields = append(fields, zap.String("RequestID", ce.requestID))
+	}
+	if ce.correlationID != "" && ce.correlationID != l.correlationID {
+		fields = append(fields, zap.String("CorrelationID", ce.correlationID))
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if l.nestContextFields {
+		return []interface{}{nestedFields("ctx", fields)}
+	}
+	args := make([]interface{}, len(fields))
+	for i, f := range fields {
+		args[i] = f
+	}
+	return args
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAttachContext(t *testing.T) {
+	errBase := errors.New("failed")
+	if err := AttachContext(context.Background(), errBase); err != errBase {
+		t.Errorf("AttachContext without IDs = %v, want the error unchanged", err)
+	}
+	if err := AttachContext(nil, errBase); err != errBase {
+		t.Errorf("AttachContext with a nil context = %v, want the error unchanged", err)
+	}
+	ctx := WithGeneratedRequestID(context.Background(), func() string { return "req-1" })
+	if err := AttachContext(ctx, nil); err != nil {
+		t.Errorf("AttachContext(nil error) = %v", err)
+	}
+
+	err := AttachContext(ctx, errBase)
+	if err.Error() != "failed" || errors.Cause(err) != errBase || !errors.Is(err, errBase) {
+		t.Errorf("err = %v, want the message and the cause unchanged", err)
+	}
+	if s := fmt.Sprintf("%+v", err); !strings.Contains(s, "attach_test.go") {
+		t.Errorf("%%+v = %q, want the stack trace of the cause", s)
+	}
+
+	l, out := newBufferLogger(t, Config{})
+	l.WithError(errors.Wrap(err, "import")).Error("m")
+	if got := out.entries(t); len(got) != 1 || got[0]["RequestID"] != "req-1" {
+		t.Errorf("entries = %v, want the attached request ID", got)
+	}
+
+	out.Reset()
+	l.With(ctx).WithError(err).Error("m")
+	if s := out.String(); strings.Count(s, `"RequestID"`) != 1 {
+		t.Errorf("output = %s, want the request ID once", s)
+	}
+}
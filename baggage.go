@@ -0,0 +1,16 @@
+//go:build !otel
+// +build !otel
+
+package log
+
+import "context"
+
+// baggageFields is a no-op unless the package is built with the "otel" tag.
+func baggageFields(ctx context.Context, keys []string) []interface{} {
+	return nil
+}
+
+// detachBaggage returns detached as it is unless the package is built with the "otel" tag.
+func detachBaggage(ctx, detached context.Context) context.Context {
+	return detached
+}
@@ -0,0 +1,37 @@
+//go:build otel
+// +build otel
+
+package log
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+)
+
+// baggageFields extracts the allowlisted OpenTelemetry baggage members from the context.
+func baggageFields(ctx context.Context, keys []string) []interface{} {
+	if len(keys) == 0 {
+		return nil
+	}
+	b := baggage.FromContext(ctx)
+	if b.Len() == 0 {
+		return nil
+	}
+	fields := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		if m := b.Member(key); m.Key() != "" {
+			fields = append(fields, zap.String(key, m.Value()))
+		}
+	}
+	return fields
+}
+
+// detachBaggage returns detached carrying the OpenTelemetry baggage of ctx, for DetachContext.
+func detachBaggage(ctx, detached context.Context) context.Context {
+	if b := baggage.FromContext(ctx); b.Len() > 0 {
+		return baggage.ContextWithBaggage(detached, b)
+	}
+	return detached
+}
@@ -0,0 +1,59 @@
+//go:build otel
+// +build otel
+
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestBaggageFields(t *testing.T) {
+	tenant, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := baggage.NewMember("secret", "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := baggage.New(tenant, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := baggage.ContextWithBaggage(context.Background(), b)
+
+	l, out := newBufferLogger(t, Config{BaggageKeys: []string{"tenant", "user"}})
+	l.With(ctx).Info("m")
+
+	entry := out.entries(t)[0]
+	if entry["tenant"] != "acme" {
+		t.Errorf("tenant = %v, want acme", entry["tenant"])
+	}
+	if _, ok := entry["secret"]; ok {
+		t.Error("the baggage member not in BaggageKeys is logged")
+	}
+	if _, ok := entry["user"]; ok {
+		t.Error("the missing baggage member is logged")
+	}
+}
+
+func TestDetachContextBaggage(t *testing.T) {
+	tenant, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := baggage.New(tenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	detached := DetachContext(baggage.ContextWithBaggage(context.Background(), b))
+
+	l, out := newBufferLogger(t, Config{BaggageKeys: []string{"tenant"}})
+	l.With(detached).Info("m")
+	if got := out.entries(t); got[0]["tenant"] != "acme" {
+		t.Errorf("tenant = %v, want the baggage of the request context", got[0]["tenant"])
+	}
+}
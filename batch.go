@@ -0,0 +1,123 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBatchSize          = 100
+	defaultBatchFlushInterval = time.Second
+)
+
+// BatchConfig makes the outputs get the entries in batches written as JSON arrays, one per line, which is cheaper
+// for some append-only stores than one object per line. It is meant for the "json" encoding.
+//
+// A batch is written when it has Size entries, FlushInterval after its first entry was logged, and on Sync.
+type BatchConfig struct {
+	// Size is the maximum number of the entries in a batch, 100 if zero
+	Size int
+	// FlushInterval is the maximum time an entry waits in a batch, one second if zero
+	FlushInterval time.Duration
+}
+
+// batchSink is a zapcore.WriteSyncer which writes the entries to out in JSON array batches.
+type batchSink struct {
+	out      zapcore.WriteSyncer
+	size     int
+	interval time.Duration
+
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	n     int
+	timer *time.Timer
+	// gen is the generation of the pending batch, so the timer of a batch already flushed does not flush the next one
+	gen    uint64
+	closed bool
+}
+
+func newBatchSink(out zapcore.WriteSyncer, conf BatchConfig) *batchSink {
+	s := &batchSink{out: out, size: conf.Size, interval: conf.FlushInterval}
+	if s.size <= 0 {
+		s.size = defaultBatchSize
+	}
+	if s.interval <= 0 {
+		s.interval = defaultBatchFlushInterval
+	}
+	return s
+}
+
+func (s *batchSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.write(p)
+}
+
+// write adds the entry to the pending batch, starting a new one if there is none. It must be called with mu held.
+func (s *batchSink) write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("Can not write to closed batch output")
+	}
+	if s.n == 0 {
+		s.buf.WriteByte('[')
+		gen := s.gen
+		s.timer = time.AfterFunc(s.interval, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.gen == gen {
+				_ = s.flush()
+			}
+		})
+	} else {
+		s.buf.WriteByte(',')
+	}
+	s.buf.Write(bytes.TrimRight(p, "\n"))
+	s.n++
+
+	if s.n >= s.size {
+		if err := s.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (s *batchSink) Sync() error {
+	s.mu.Lock()
+	err := s.flush()
+	s.mu.Unlock()
+
+	if syncErr := s.out.Sync(); err == nil {
+		err = syncErr
+	}
+	return err
+}
+
+// Close writes the pending batch, if any, and stops the flush timer. The later writes fail. It does not close
+// the output.
+func (s *batchSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	return s.flush()
+}
+
+// flush writes the pending batch, if any. It must be called with mu held.
+func (s *batchSink) flush() error {
+	if s.n == 0 {
+		return nil
+	}
+	s.timer.Stop()
+	s.buf.WriteString("]\n")
+	_, err := s.out.Write(s.buf.Bytes())
+	s.buf.Reset()
+	s.n = 0
+	s.gen++
+	return err
+}
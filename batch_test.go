@@ -0,0 +1,104 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// batches decodes the JSON array lines written by a batchSink.
+func batches(t *testing.T, s string) [][]map[string]interface{} {
+	t.Helper()
+	var res [][]map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line == "" {
+			continue
+		}
+		var batch []map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &batch); err != nil {
+			t.Fatalf("%v: %s", err, line)
+		}
+		res = append(res, batch)
+	}
+	return res
+}
+
+func TestBatchWrites(t *testing.T) {
+	l, out := newBufferLogger(t, Config{BatchWrites: &BatchConfig{Size: 2, FlushInterval: time.Hour}})
+	// the construction entry may be pending in the batch
+	l.Sync()
+	out.Reset()
+
+	for i := 0; i < 3; i++ {
+		l.Infow("m", "i", i)
+	}
+	if got := batches(t, out.String()); len(got) != 1 || len(got[0]) != 2 || got[0][1]["i"] != float64(1) {
+		t.Fatalf("batches = %v, want a full batch of 2 entries", got)
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := batches(t, out.String()); len(got) != 2 || len(got[1]) != 1 || got[1][0]["i"] != float64(2) {
+		t.Errorf("batches = %v, want the pending entry flushed by Sync", got)
+	}
+}
+
+func TestBatchFlushInterval(t *testing.T) {
+	out := &bufferSyncer{}
+	s := newBatchSink(out, BatchConfig{FlushInterval: time.Millisecond})
+	s.Write([]byte(`{"i":0}` + "\n"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := out.String(); got != `[{"i":0}]`+"\n" {
+		t.Errorf("output = %q, want the batch flushed after the interval", got)
+	}
+}
+
+func TestBatchWritesSynchronous(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Synchronous: true, BatchWrites: &BatchConfig{Size: 2, FlushInterval: time.Hour}})
+	l.Info("m")
+	if got := out.entries(t); len(got) != 1 || got[0]["message"] != "m" {
+		t.Errorf("entries = %v, want the entry written without batching", got)
+	}
+}
+
+func TestBatchClose(t *testing.T) {
+	out := &bufferSyncer{}
+	s := newBatchSink(out, BatchConfig{FlushInterval: time.Hour})
+	s.Write([]byte(`{"i":0}` + "\n"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write([]byte(`{"i":1}` + "\n")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != `[{"i":0}]`+"\n" {
+		t.Errorf("output = %q, want only the batch pending on Close", got)
+	}
+}
+
+func TestBatchStaleTimer(t *testing.T) {
+	out := &bufferSyncer{}
+	s := newBatchSink(out, BatchConfig{FlushInterval: time.Millisecond})
+	s.Write([]byte(`{"i":0}` + "\n"))
+
+	// the timer of the first batch fires while the batch is flushed and the next one is started
+	s.mu.Lock()
+	time.Sleep(20 * time.Millisecond)
+	s.flush()
+	s.interval = time.Hour
+	s.write([]byte(`{"i":1}` + "\n"))
+	s.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+
+	if got := out.String(); got != `[{"i":0}]`+"\n" {
+		t.Errorf("output = %q, want the next batch pending", got)
+	}
+}
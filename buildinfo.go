@@ -0,0 +1,19 @@
+package log
+
+import (
+	"context"
+	"runtime"
+)
+
+// LogBuildInfo logs at INFO level a "Build info" message meant as the first entry of a service, with
+// the "version", "commit" and "build_date" fields given by the build and the "go_version" and "num_cpu"
+// fields of the runtime.
+func (l *logger) LogBuildInfo(ctx context.Context, version, commit, date string) {
+	l.With(ctx).skipCaller().Infow("Build info",
+		"version", version,
+		"commit", commit,
+		"build_date", date,
+		"go_version", runtime.Version(),
+		"num_cpu", runtime.NumCPU(),
+	)
+}
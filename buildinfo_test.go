@@ -0,0 +1,30 @@
+package log
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLogBuildInfo(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	l.LogBuildInfo(context.Background(), "1.2.3", "abc123", "2026-01-02")
+
+	entry := out.entries(t)[0]
+	for key, want := range map[string]interface{}{
+		"message":    "Build info",
+		"version":    "1.2.3",
+		"commit":     "abc123",
+		"build_date": "2026-01-02",
+		"go_version": runtime.Version(),
+		"num_cpu":    float64(runtime.NumCPU()),
+	} {
+		if entry[key] != want {
+			t.Errorf("%s = %v, want %v", key, entry[key], want)
+		}
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "buildinfo_test.go") {
+		t.Errorf("caller = %q, want the line calling LogBuildInfo", caller)
+	}
+}
@@ -0,0 +1,43 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// skipCaller returns the sugared logger of l reporting the caller of the method which calls skipCaller,
+// for the logger methods wrapping the logging calls. It is built once by setSugar, so the wrapping methods
+// cost nothing more than the zap ones when the level is disabled.
+func (l *logger) skipCaller() *zap.SugaredLogger {
+	return l.callerSkipped
+}
+
+// setSugar sets the sugared logger of l, along with the one returned by skipCaller.
+func (l *logger) setSugar(s *zap.SugaredLogger) {
+	l.SugaredLogger = s
+	l.callerSkipped = s.Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar()
+}
+
+// callerPackage returns the import path of the package of the entry caller, or "" if the caller is unknown.
+func callerPackage(caller zapcore.EntryCaller) string {
+	if !caller.Defined {
+		return ""
+	}
+	fn := runtime.FuncForPC(caller.PC)
+	if fn == nil {
+		return ""
+	}
+	return funcPackage(fn.Name())
+}
+
+// funcPackage returns the package path of a fully qualified function name like "github.com/minipkg/log.(*logger).With".
+func funcPackage(name string) string {
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
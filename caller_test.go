@@ -0,0 +1,48 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/minipkg/log"
+)
+
+// TestIncludeCallerPackage logs from outside of the log package, so the package of the caller differs from
+// the package of the logger.
+func TestIncludeCallerPackage(t *testing.T) {
+	var out bytes.Buffer
+	l, err := log.NewWithSyncer(zapcore.AddSync(&out), log.Config{
+		Encoding:             "json",
+		IncludeCallerPackage: true,
+		CallerLevels:         []string{"error"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	l.Error("with caller")
+	l.Errorf("with caller %d", 1)
+	l.Info("without caller")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("output = %q, want 3 entries", out.String())
+	}
+	for i, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		pkg, ok := entry["pkg"]
+		if i < 2 && pkg != "github.com/minipkg/log_test" {
+			t.Errorf("pkg of %q = %v, want github.com/minipkg/log_test", entry["message"], pkg)
+		}
+		if i == 2 && ok {
+			t.Errorf("pkg = %q added to an entry without a caller", pkg)
+		}
+	}
+}
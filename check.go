@@ -0,0 +1,22 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Check returns a CheckedEntry if logging a message at the level is enabled, or nil otherwise. Expensive fields
+// are then only computed when they are going to be logged:
+//
+//	if ce := logger.Check(zap.DebugLevel, "Cache state"); ce != nil {
+//		ce.Write(zap.Any("entries", cache.Dump()))
+//	}
+//
+// The entry reports the caller of Check.
+func (l *logger) Check(level zapcore.Level, msg string) *zapcore.CheckedEntry {
+	zl := l.SugaredLogger.Desugar()
+	if !zl.Core().Enabled(level) {
+		return nil
+	}
+	return zl.WithOptions(zap.AddCallerSkip(1)).Check(level, msg)
+}
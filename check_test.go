@@ -0,0 +1,28 @@
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestCheck(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info"})
+	if ce := l.Check(zap.DebugLevel, "disabled"); ce != nil {
+		t.Error("Check returned an entry for a disabled level")
+	}
+	ce := l.Check(zap.InfoLevel, "enabled")
+	if ce == nil {
+		t.Fatal("Check returned nil for an enabled level")
+	}
+	ce.Write(zap.Int("size", 3))
+
+	entry := out.entries(t)[0]
+	if entry["message"] != "enabled" || entry["size"] != float64(3) {
+		t.Errorf("entry = %v, want the checked entry with its field", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "check_test.go") {
+		t.Errorf("caller = %q, want the line calling Check", caller)
+	}
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// LogIfErr runs fn and logs the error it returns, if any, at WARN level with the message and the "error" field.
+// It is meant for fire-and-forget cleanup like
+//
+//	defer logger.LogIfErr(ctx, "Can not remove the temporary file", func() error { return os.Remove(name) })
+func (l *logger) LogIfErr(ctx context.Context, msg string, fn func() error) {
+	if err := fn(); err != nil {
+		l.With(ctx).skipCaller().Warnw(msg, zap.Error(err))
+	}
+}
+
+// CloseAndLog closes c and logs the error it returns, if any, like LogIfErr.
+//
+//	defer logger.CloseAndLog(ctx, rows, "Can not close the rows")
+func (l *logger) CloseAndLog(ctx context.Context, c io.Closer, msg string) {
+	if err := c.Close(); err != nil {
+		l.With(ctx).skipCaller().Warnw(msg, zap.Error(err))
+	}
+}
+
+// WrapErr logs err at ERROR level with the message and the "error" field, and returns it wrapped with the message
+// by errors.Wrap, so an error can be logged and returned in one expression:
+//
+//	return logger.WrapErr(ctx, err, "Can not save the user")
+//
+// Nothing is logged and nil is returned if err is nil.
+func (l *logger) WrapErr(ctx context.Context, err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	l.With(ctx).skipCaller().Errorw(msg, zap.Error(err))
+	return errors.Wrap(err, msg)
+}
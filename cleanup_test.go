@@ -0,0 +1,56 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// closerFunc is an io.Closer calling the func.
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestLogIfErr(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	ctx := context.Background()
+	l.LogIfErr(ctx, "ok", func() error { return nil })
+	l.LogIfErr(ctx, "Can not remove", func() error { return errors.New("busy") })
+	l.CloseAndLog(ctx, closerFunc(func() error { return nil }), "ok")
+	l.CloseAndLog(ctx, closerFunc(func() error { return errors.New("closed") }), "Can not close")
+
+	entries := out.entries(t)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %v, want the failures only", entries)
+	}
+	for i, want := range [][2]string{{"Can not remove", "busy"}, {"Can not close", "closed"}} {
+		e := entries[i]
+		if e["level"] != "warn" || e["message"] != want[0] || e["error"] != want[1] {
+			t.Errorf("entry %d = %v, want a WARN entry %q with error %q", i, e, want[0], want[1])
+		}
+		if caller, _ := e["caller"].(string); !strings.Contains(caller, "cleanup_test.go") {
+			t.Errorf("caller %d = %q, want the line of the test", i, caller)
+		}
+	}
+}
+
+func TestWrapErr(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	if err := l.WrapErr(context.Background(), nil, "ok"); err != nil {
+		t.Errorf("WrapErr(nil) = %v", err)
+	}
+	cause := errors.New("duplicate key")
+	err := l.WrapErr(context.Background(), cause, "Can not save the user")
+	if err == nil || err.Error() != "Can not save the user: duplicate key" || !errors.Is(err, cause) {
+		t.Errorf("WrapErr = %v, want the wrapped cause", err)
+	}
+
+	entries := out.entries(t)
+	if len(entries) != 1 || entries[0]["level"] != "error" || entries[0]["error"] != "duplicate key" {
+		t.Errorf("entries = %v, want a single ERROR entry with the cause", entries)
+	}
+	if caller, _ := entries[0]["caller"].(string); !strings.Contains(caller, "cleanup_test.go") {
+		t.Errorf("caller = %q, want the line calling WrapErr", caller)
+	}
+}
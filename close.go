@@ -0,0 +1,25 @@
+package log
+
+import "sync"
+
+// closer closes the outputs of a logger built by New, which it shares with the loggers derived from it.
+type closer struct {
+	once  sync.Once
+	close func()
+}
+
+// Close flushes the entries buffered by the outputs, stops their background writers and closes them,
+// so no entry logged before Close is lost. The logger and the loggers derived from it must not be used
+// afterward. Only the first Close has an effect; Close only syncs the loggers not created by New.
+func (l *logger) Close() error {
+	if l.closer == nil {
+		return l.Sync()
+	}
+
+	var err error
+	l.closer.once.Do(func() {
+		err = l.Sync()
+		l.closer.close()
+	})
+	return err
+}
@@ -0,0 +1,56 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCloseDrainsBatches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.log")
+
+	l, err := New(Config{
+		Encoding:    "json",
+		OutputPaths: []string{path},
+		BatchWrites: &BatchConfig{Size: 1000, FlushInterval: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 500
+	for i := 0; i < n; i++ {
+		l.Infow("entry", "i", i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := 0
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var batch []map[string]interface{}
+		if err := json.Unmarshal(line, &batch); err != nil {
+			t.Fatalf("invalid batch %q: %v", line, err)
+		}
+		got += len(batch)
+	}
+	// the entries and the "Logger construction succeeded" one
+	if got != n+1 {
+		t.Errorf("got %d entries, want %d", got, n+1)
+	}
+}
@@ -0,0 +1,18 @@
+package log
+
+import (
+	"context"
+	"time"
+)
+
+// ConnClose logs at INFO level the summary of a long-lived connection, like a server-sent event stream or
+// a WebSocket, closed in the context. The access log is written when the handler returns, which is too early
+// for such connections. The message gets the "duration" (in milliseconds since started), "bytes_out" and
+// "msgs_out" fields.
+func (l *logger) ConnClose(ctx context.Context, started time.Time, bytesOut, msgsOut int64) {
+	l.With(ctx).skipCaller().Infow("Connection closed",
+		"duration", time.Since(started).Milliseconds(),
+		"bytes_out", bytesOut,
+		"msgs_out", msgsOut,
+	)
+}
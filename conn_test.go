@@ -0,0 +1,29 @@
+package log
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnClose(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	ctx := WithRequest(context.Background(), req)
+
+	l.ConnClose(ctx, time.Now().Add(-time.Second), 1024, 3)
+
+	entry := out.entries(t)[0]
+	if d, _ := entry["duration"].(float64); d < 1000 {
+		t.Errorf("duration = %v, want at least 1000", entry["duration"])
+	}
+	if entry["bytes_out"] != float64(1024) || entry["msgs_out"] != float64(3) || entry["RequestID"] != "req-1" {
+		t.Errorf("entry = %v, want the connection summary with the request ID", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "conn_test.go") {
+		t.Errorf("caller = %q, want the line calling ConnClose", caller)
+	}
+}
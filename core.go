@@ -0,0 +1,114 @@
+package log
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// wrapCore decorates the core built from conf by the cores of the enabled features.
+//
+// The sampler is always installed, so SetSampling can enable the sampling later. It stays the outermost core
+// apart from the levelCore, so the entries it drops are not rewritten by the other wrappers in vain.
+func wrapCore(core zapcore.Core, conf Config, level zapcore.LevelEnabler, sampling *samplingSwitch, counts *entryCounts) *levelCore {
+	core = rewriteEntries(core, conf)
+	if conf.SpanEventLevel != "" {
+		// the level is validated by configToZapConfig
+		lvl, _ := parseLevel(conf.SpanEventLevel)
+		core = &spanEventCore{Core: core, enab: lvl}
+	}
+	if counts != nil {
+		// the entries are counted once the sampler has let them through
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			counts.inc(ent.Level)
+			return ent, fields
+		}}
+	}
+	core = newSampler(core, sampling)
+	return &levelCore{Core: core, enab: level}
+}
+
+// rewriteEntries decorates the core by the cores of the enabled features which rewrite the entries, so
+// the DebugMirror gets the entries rewritten the same way as the outputs.
+func rewriteEntries(core zapcore.Core, conf Config) zapcore.Core {
+	if conf.Preset == presetECS {
+		core = &rewriteCore{Core: core, rewrite: ecsFields}
+	}
+	if conf.StacktraceIf != nil {
+		core = &rewriteCore{Core: core, rewrite: stacktraceIf(conf.StacktraceIf)}
+	}
+	if conf.IncludeNumericLevel {
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			return ent, append(fields, zap.Int("level_num", int(ent.Level)))
+		}}
+	}
+	if conf.IncludeCallerPackage {
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			if ent.Caller.Defined {
+				fields = append(fields, zap.String("pkg", callerPackage(ent.Caller)))
+			}
+			return ent, fields
+		}}
+	}
+	if conf.IncludeFunction {
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			// zap resolves the function by runtime.CallersFrames, skipping the frames of the logger wrappers
+			if ent.Caller.Defined && ent.Caller.Function != "" {
+				fields = append(fields, zap.String("func", ent.Caller.Function))
+			}
+			return ent, fields
+		}}
+	}
+	if conf.IncludeGoroutineID {
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			return ent, append(fields, zap.Uint64("goroutine", goroutineID()))
+		}}
+	}
+	if conf.IncludeUptime {
+		start := time.Now()
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			return ent, append(fields, zap.Int64("uptime_ms", ent.Time.Sub(start).Milliseconds()))
+		}}
+	}
+	if conf.MaxMessageBytes > 0 {
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			ent.Message = truncate(ent.Message, conf.MaxMessageBytes)
+			return ent, fields
+		}}
+	}
+	if conf.CallerLevels != nil {
+		// the levels are validated by configToZapConfig
+		levels, _ := parseLevels(conf.CallerLevels)
+		// the caller is removed before the other wrappers see it
+		core = &rewriteCore{Core: core, rewrite: func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+			if !levels[ent.Level] {
+				ent.Caller = zapcore.EntryCaller{}
+			}
+			return ent, fields
+		}}
+	}
+	return core
+}
+
+// rewriteCore is a zapcore.Core which rewrites every entry and its fields before writing them.
+type rewriteCore struct {
+	zapcore.Core
+	rewrite func(zapcore.Entry, []zapcore.Field) (zapcore.Entry, []zapcore.Field)
+}
+
+func (c *rewriteCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rewriteCore{Core: c.Core.With(fields), rewrite: c.rewrite}
+}
+
+func (c *rewriteCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *rewriteCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent, fields = c.rewrite(ent, fields)
+	return c.Core.Write(ent, fields)
+}
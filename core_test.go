@@ -0,0 +1,85 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintCaller(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "debug"})
+	l.Print("print")
+	l.Printf("printf %d", 1)
+
+	for _, entry := range out.entries(t) {
+		if caller, _ := entry["caller"].(string); !strings.Contains(caller, "core_test.go") {
+			t.Errorf("caller = %q, want the line calling %q", caller, entry["message"])
+		}
+	}
+}
+
+func TestIncludeGoroutineID(t *testing.T) {
+	l, out := newBufferLogger(t, Config{IncludeGoroutineID: true})
+	l.Info("main")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Info("other")
+	}()
+	<-done
+
+	entries := out.entries(t)
+	main, _ := entries[0]["goroutine"].(float64)
+	other, _ := entries[1]["goroutine"].(float64)
+	if main == 0 || other == 0 || main == other {
+		t.Errorf("goroutine IDs = %v and %v, want two distinct IDs", entries[0]["goroutine"], entries[1]["goroutine"])
+	}
+}
+
+func TestIncludeNumericLevel(t *testing.T) {
+	l, out := newBufferLogger(t, Config{IncludeNumericLevel: true})
+	l.Info("info")
+	l.Error("error")
+
+	entries := out.entries(t)
+	if entries[0]["level_num"] != float64(0) || entries[1]["level_num"] != float64(2) {
+		t.Errorf("level_num = %v and %v, want 0 and 2", entries[0]["level_num"], entries[1]["level_num"])
+	}
+}
+
+func TestIncludeUptime(t *testing.T) {
+	l, out := newBufferLogger(t, Config{IncludeUptime: true})
+	time.Sleep(5 * time.Millisecond)
+	l.Info("m")
+
+	if uptime, _ := out.entries(t)[0]["uptime_ms"].(float64); uptime < 5 {
+		t.Errorf("uptime_ms = %v, want at least 5", uptime)
+	}
+}
+
+func TestIncludeFunction(t *testing.T) {
+	l, out := newBufferLogger(t, Config{IncludeFunction: true})
+	l.Info("m")
+
+	if fn, _ := out.entries(t)[0]["func"].(string); !strings.HasSuffix(fn, ".TestIncludeFunction") {
+		t.Errorf("func = %q, want the test function", fn)
+	}
+}
+
+func TestCallerLevels(t *testing.T) {
+	l, out := newBufferLogger(t, Config{CallerLevels: []string{"warn", "error"}})
+	l.Info("info")
+	l.Warn("warn")
+
+	entries := out.entries(t)
+	if _, ok := entries[0]["caller"]; ok {
+		t.Error("the INFO entry has the caller")
+	}
+	if _, ok := entries[1]["caller"]; !ok {
+		t.Error("the WARN entry has no caller")
+	}
+
+	if _, err := New(Config{Encoding: "json", CallerLevels: []string{"verbose"}}); err == nil {
+		t.Error("New accepted an invalid caller level")
+	}
+}
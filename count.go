@@ -0,0 +1,35 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// entryCounts counts the entries written per level.
+type entryCounts struct {
+	// counts are indexed by the level as uint8, so the levels registered by RegisterLevel are counted as well
+	counts [256]int64
+}
+
+func (c *entryCounts) inc(lvl zapcore.Level) {
+	atomic.AddInt64(&c.counts[uint8(lvl)], 1)
+}
+
+// EntryCount returns the number of the entries the logger built by New and all the loggers derived from it
+// have written since the logger was built, per level. The levels without entries are omitted. It returns nil
+// unless Config.CountEntries is set.
+//
+// The entries sampled out are not counted.
+func (l *logger) EntryCount() map[zapcore.Level]int64 {
+	if l.counts == nil {
+		return nil
+	}
+	m := make(map[zapcore.Level]int64)
+	for i := range l.counts.counts {
+		if n := atomic.LoadInt64(&l.counts.counts[i]); n > 0 {
+			m[zapcore.Level(int8(uint8(i)))] = n
+		}
+	}
+	return m
+}
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEntryCount(t *testing.T) {
+	l, _ := newBufferLogger(t, Config{CountEntries: true, Sampling: &SamplingConfig{Initial: 1, Tick: time.Hour}})
+	before := l.EntryCount()[zapcore.InfoLevel]
+
+	l.Warn("a")
+	l.With(context.Background(), "derived", true).Warn("b")
+	l.Debug("disabled")
+	// the second one is sampled out
+	l.Info("sampled")
+	l.Info("sampled")
+
+	counts := l.EntryCount()
+	if counts[zapcore.WarnLevel] != 2 || counts[zapcore.InfoLevel] != before+1 {
+		t.Errorf("counts = %v, want 2 WARN and %d INFO entries", counts, before+1)
+	}
+	if _, ok := counts[zapcore.DebugLevel]; ok {
+		t.Errorf("counts = %v, want no DEBUG entries", counts)
+	}
+
+	l, _ = newBufferLogger(t, Config{})
+	if counts := l.EntryCount(); counts != nil {
+		t.Errorf("EntryCount() = %v without CountEntries, want nil", counts)
+	}
+}
@@ -0,0 +1,35 @@
+package log
+
+import "context"
+
+// DebugCtxf decorates the logger with the context like With does and logs a message at DEBUG level
+// constructed by fmt.Sprintf.
+func (l *logger) DebugCtxf(ctx context.Context, format string, args ...interface{}) {
+	lw := l.With(ctx)
+	lw.checkFormat(format, args)
+	lw.skipCaller().Debugf(format, args...)
+}
+
+// InfoCtxf decorates the logger with the context like With does and logs a message at INFO level
+// constructed by fmt.Sprintf.
+func (l *logger) InfoCtxf(ctx context.Context, format string, args ...interface{}) {
+	lw := l.With(ctx)
+	lw.checkFormat(format, args)
+	lw.skipCaller().Infof(format, args...)
+}
+
+// WarnCtxf decorates the logger with the context like With does and logs a message at WARN level
+// constructed by fmt.Sprintf.
+func (l *logger) WarnCtxf(ctx context.Context, format string, args ...interface{}) {
+	lw := l.With(ctx)
+	lw.checkFormat(format, args)
+	lw.skipCaller().Warnf(format, args...)
+}
+
+// ErrorCtxf decorates the logger with the context like With does and logs a message at ERROR level
+// constructed by fmt.Sprintf.
+func (l *logger) ErrorCtxf(ctx context.Context, format string, args ...interface{}) {
+	lw := l.With(ctx)
+	lw.checkFormat(format, args)
+	lw.skipCaller().Errorf(format, args...)
+}
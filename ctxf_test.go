@@ -0,0 +1,30 @@
+package log
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCtxf(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	ctx := WithRequest(context.Background(), req)
+
+	l.ErrorCtxf(ctx, "failed %d times", 3)
+	entry := out.entries(t)[0]
+	if entry["level"] != "error" || entry["message"] != "failed 3 times" || entry["RequestID"] != "req-1" {
+		t.Errorf("entry = %v, want an ERROR entry with the request ID", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "ctxf_test.go") {
+		t.Errorf("caller = %q, want the line calling ErrorCtxf", caller)
+	}
+
+	out.Reset()
+	l.With(ctx).InfoCtxf(ctx, "again")
+	if n := strings.Count(out.String(), `"RequestID"`); n != 1 {
+		t.Errorf("RequestID added %d times: %s", n, out.String())
+	}
+}
@@ -0,0 +1,36 @@
+package log
+
+import "context"
+
+// IntoContext returns a context carrying the logger, which is retrieved by FromContext.
+func IntoContext(ctx context.Context, l *logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in the context by IntoContext or WithContext, or Default() if there is none.
+func FromContext(ctx context.Context) *logger {
+	if l, ok := ctx.Value(loggerKey).(*logger); ok {
+		return l
+	}
+	return Default()
+}
+
+// WithContext returns the logger decorated by With(ctx, args...) and a context carrying it,
+// so the functions called with the context may log via FromContext.
+func (l *logger) WithContext(ctx context.Context, args ...interface{}) (context.Context, *logger) {
+	nl := l.With(ctx, args...)
+	return IntoContext(ctx, nl), nl
+}
+
+// WithTenant returns a context carrying the tenant ID and the logger decorated by With for it, which is also
+// stored in the context for FromContext. The loggers derived via With from the context or its descendants
+// get the "tenant_id" field.
+func (l *logger) WithTenant(ctx context.Context, tenantID string) (context.Context, *logger) {
+	return l.WithContext(context.WithValue(ctx, tenantKey, tenantID))
+}
+
+// Tenant returns the tenant ID recorded in the context by WithTenant, or "" if there is none.
+func Tenant(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey).(string)
+	return id
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWithContext(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	if FromContext(context.Background()) != Default() {
+		t.Error("FromContext without a logger did not return Default()")
+	}
+
+	ctx, nl := l.WithContext(context.Background(), "job", "import")
+	if FromContext(ctx) != nl {
+		t.Error("FromContext did not return the logger of WithContext")
+	}
+	FromContext(ctx).Info("m")
+	if entry := out.entries(t)[0]; entry["job"] != "import" {
+		t.Errorf("job = %v, want import", entry["job"])
+	}
+}
+
+func TestWithTenant(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	ctx, tl := l.WithTenant(context.Background(), "acme")
+	if Tenant(ctx) != "acme" || FromContext(ctx) != tl {
+		t.Errorf("Tenant = %q, want acme and the tenant logger in the context", Tenant(ctx))
+	}
+
+	tl.Info("tenant logger")
+	l.With(DetachContext(ctx)).Info("derived")
+	// the tenant is not added again
+	tl.With(ctx).Info("again")
+
+	for i, entry := range out.entries(t) {
+		if entry["tenant_id"] != "acme" {
+			t.Errorf("entry %d tenant_id = %v, want acme", i, entry["tenant_id"])
+		}
+	}
+	if n := strings.Count(out.String(), `"tenant_id"`); n != 3 {
+		t.Errorf("tenant_id logged %d times, want once per entry", n)
+	}
+}
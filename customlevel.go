@@ -0,0 +1,77 @@
+package log
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// customLevels is the registry of the levels registered by RegisterLevel.
+var customLevels = struct {
+	sync.RWMutex
+	byName  map[string]zapcore.Level
+	byLevel map[zapcore.Level]string
+}{
+	byName:  make(map[string]zapcore.Level),
+	byLevel: make(map[zapcore.Level]string),
+}
+
+// RegisterLevel registers a custom level, like "trace", which is then accepted by Config.Level, SetLevel and
+// the other level names and rendered by its name. The zap levels from DEBUG to FATAL are consecutive, so the value
+// must lie below DEBUG, e.g. -2 for "trace"; the levels above FATAL would stay enabled at the "off" level.
+// The custom levels are logged by Log.
+func RegisterLevel(name string, lvl zapcore.Level) error {
+	if lvl >= zapcore.DebugLevel {
+		return errors.Errorf("Can not register level %q, %d is not below DEBUG", name, lvl)
+	}
+	var std zapcore.Level
+	if name == "off" || name == "silent" || std.UnmarshalText([]byte(name)) == nil {
+		return errors.Errorf("Can not register level %q, the name is taken by a standard level", name)
+	}
+
+	customLevels.Lock()
+	defer customLevels.Unlock()
+
+	if other, ok := customLevels.byLevel[lvl]; ok && other != name {
+		return errors.Errorf("Can not register level %q, %d is taken by level %q", name, lvl, other)
+	}
+	customLevels.byName[name] = lvl
+	customLevels.byLevel[lvl] = name
+	return nil
+}
+
+func customLevel(name string) (zapcore.Level, bool) {
+	customLevels.RLock()
+	defer customLevels.RUnlock()
+
+	lvl, ok := customLevels.byName[name]
+	return lvl, ok
+}
+
+func customLevelName(lvl zapcore.Level) (string, bool) {
+	customLevels.RLock()
+	defer customLevels.RUnlock()
+
+	name, ok := customLevels.byLevel[lvl]
+	return name, ok
+}
+
+// levelEncoder encodes the custom levels by their names and the standard ones like zapcore.LowercaseLevelEncoder.
+func levelEncoder(lvl zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if name, ok := customLevelName(lvl); ok {
+		enc.AppendString(name)
+		return
+	}
+	zapcore.LowercaseLevelEncoder(lvl, enc)
+}
+
+// Log logs a message at the level, which may be a custom one, with the key and value pairs like Infow does.
+func (l *logger) Log(level zapcore.Level, msg string, keysAndValues ...interface{}) {
+	if !l.SugaredLogger.Desugar().Core().Enabled(level) {
+		return
+	}
+	if ce := l.skipCaller().With(keysAndValues...).Desugar().Check(level, msg); ce != nil {
+		ce.Write()
+	}
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRegisterLevel(t *testing.T) {
+	const trace = zapcore.Level(-2)
+	if err := RegisterLevel("trace", trace); err != nil {
+		t.Fatal(err)
+	}
+
+	l, out := newBufferLogger(t, Config{Level: "trace"})
+	l.Log(trace, "tracing", "k", "v")
+	entries := out.entries(t)
+	if len(entries) != 1 || entries[0]["level"] != "trace" || entries[0]["k"] != "v" {
+		t.Fatalf("entries = %v, want one trace entry", entries)
+	}
+
+	if err := l.SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	l.Log(trace, "tracing")
+	if s := out.String(); s != "" {
+		t.Errorf("trace entry logged at DEBUG level: %s", s)
+	}
+}
+
+func TestRegisterLevelRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		lvl  zapcore.Level
+	}{
+		{"notice", zapcore.InfoLevel},
+		{"emergency", zapcore.FatalLevel + 2},
+		{"debug", -3},
+		{"off", -4},
+	}
+	for _, tt := range tests {
+		if err := RegisterLevel(tt.name, tt.lvl); err == nil {
+			t.Errorf("RegisterLevel(%q, %d) succeeded", tt.name, tt.lvl)
+		}
+	}
+
+	if err := RegisterLevel("fine", -5); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterLevel("finer", -5); err == nil {
+		t.Error("RegisterLevel succeeded for a taken value")
+	}
+}
@@ -0,0 +1,33 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+var (
+	defaultLogger atomic.Value // *logger
+
+	nopOnce sync.Once
+	nop     *logger
+)
+
+// Default returns the logger set by SetDefault, for libraries which log without being given a logger.
+// Until SetDefault is called it returns a logger discarding everything, so importing a library has no
+// side effects on the output of the application.
+func Default() *logger {
+	if l, ok := defaultLogger.Load().(*logger); ok {
+		return l
+	}
+	nopOnce.Do(func() {
+		nop = NewWithZap(zap.NewNop())
+	})
+	return nop
+}
+
+// SetDefault sets the logger returned by Default, usually once by the application at startup.
+func SetDefault(l *logger) {
+	defaultLogger.Store(l)
+}
@@ -0,0 +1,23 @@
+package log
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDefault(t *testing.T) {
+	if Default() != Default() {
+		t.Error("Default returned different no-op loggers")
+	}
+	if Default().Desugar().Core().Enabled(zapcore.ErrorLevel) {
+		t.Error("the logger returned before SetDefault is not a no-op")
+	}
+
+	l, out := newBufferLogger(t, Config{})
+	SetDefault(l)
+	Default().Info("m")
+	if entries := out.entries(t); len(entries) != 1 {
+		t.Errorf("entries = %v, want the entry of the default logger", entries)
+	}
+}
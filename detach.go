@@ -0,0 +1,21 @@
+package log
+
+import "context"
+
+// DetachContext returns a context for a goroutine which outlives the request of ctx. It is derived from
+// context.Background() and so is never canceled, but it carries the logging values of ctx: the request ID,
+// the correlation ID, the route, the tenant ID, the sampling key, the logger stored by IntoContext and
+// the OpenTelemetry baggage. The application's own values read by RegisterContextExtractor are not copied,
+// but the fields the extractors return for ctx are, so With adds them for the detached context as well.
+func DetachContext(ctx context.Context) context.Context {
+	detached := context.Background()
+	for _, key := range []contextKey{requestIDKey, correlationIDKey, routeKey, tenantKey, samplingKeyKey, loggerKey} {
+		if val := ctx.Value(key); val != nil {
+			detached = context.WithValue(detached, key, val)
+		}
+	}
+	if fields := extractedFields(ctx); len(fields) > 0 {
+		detached = context.WithValue(detached, extractedFieldsKey, fields)
+	}
+	return detachBaggage(ctx, detached)
+}
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetachContext(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	ctx, cancel := context.WithCancel(WithRequest(context.Background(), req))
+	detached := DetachContext(ctx)
+	cancel()
+
+	if detached.Err() != nil {
+		t.Errorf("the detached context is done: %v", detached.Err())
+	}
+	if RequestID(detached) != "req-1" || CorrelationID(detached) != "corr-1" {
+		t.Errorf("detached IDs = %q, %q, want req-1, corr-1", RequestID(detached), CorrelationID(detached))
+	}
+}
+
+func TestDetachContextExtractedFields(t *testing.T) {
+	ctx := context.WithValue(context.Background(), extractorTestKey{}, &extractorTestMeta{UserID: "u1"})
+	detached := DetachContext(ctx)
+
+	l, out := newBufferLogger(t, Config{})
+	l.With(detached).Info("m")
+	if got := out.entries(t); got[0]["user_id"] != "u1" {
+		t.Errorf("user_id = %v, want the extracted field of the request context", got[0]["user_id"])
+	}
+}
@@ -0,0 +1,170 @@
+package log
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// newEncoder creates the encoder named by cfg.Encoding decorated by the encoders of the features enabled in conf.
+func newEncoder(cfg zap.Config, conf Config) (zapcore.Encoder, error) {
+	var enc zapcore.Encoder
+	switch cfg.Encoding {
+	case "json":
+		enc = zapcore.NewJSONEncoder(cfg.EncoderConfig)
+	case "console":
+		enc = zapcore.NewConsoleEncoder(cfg.EncoderConfig)
+		sanitize := conf.SanitizeControlChars == nil || *conf.SanitizeControlChars
+		if sanitize || conf.PreserveNewlines {
+			enc = &messageEncoder{Encoder: enc, sanitize: sanitize, preserveNewlines: conf.PreserveNewlines}
+		}
+	case "proto":
+		enc = newProtoEncoder()
+	default:
+		return nil, errors.Errorf("Unknown encoding %q, expected json, console or proto", cfg.Encoding)
+	}
+
+	if conf.FlattenNested {
+		enc = &flatEncoder{Encoder: enc}
+	}
+	if conf.MaxFieldBytes > 0 {
+		enc = newFieldLimitEncoder(enc, conf.MaxFieldBytes)
+	}
+	conv, err := keyCaseConverter(conf.KeyCase)
+	if err != nil {
+		return nil, err
+	}
+	if conv != nil {
+		enc = newKeyCaseEncoder(enc, conv)
+	}
+	return enc, nil
+}
+
+// messageEncoder is a zapcore.Encoder for the console encoding, which writes the messages verbatim unlike
+// the field values. It escapes the control characters of the messages if sanitize is set, so a message can not
+// forge log lines, and indents the continuation lines of multi-line messages by a tab if preserveNewlines is set,
+// so the newlines are kept for readability while every entry still starts on an unindented line.
+type messageEncoder struct {
+	zapcore.Encoder
+	sanitize         bool
+	preserveNewlines bool
+}
+
+func (e *messageEncoder) Clone() zapcore.Encoder {
+	return &messageEncoder{Encoder: e.Encoder.Clone(), sanitize: e.sanitize, preserveNewlines: e.preserveNewlines}
+}
+
+func (e *messageEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if e.preserveNewlines {
+		ent.Message = strings.TrimRight(ent.Message, "\n")
+	}
+	if e.sanitize {
+		ent.Message = escapeControlChars(ent.Message, e.preserveNewlines)
+	}
+	if e.preserveNewlines {
+		ent.Message = strings.ReplaceAll(ent.Message, "\n", "\n\t")
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// escapeControlChars escapes the control characters of s except tabs, and newlines if keepNewlines is set,
+// like strconv.Quote does, e.g. "\r\n" becomes `\r\n`.
+func escapeControlChars(s string, keepNewlines bool) string {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsControl(r) && r != '\t' && !(keepNewlines && r == '\n')
+	})
+	if i < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+	for _, r := range s[i:] {
+		if !unicode.IsControl(r) || r == '\t' || keepNewlines && r == '\n' {
+			b.WriteRune(r)
+			continue
+		}
+		q := strconv.QuoteRune(r)
+		b.WriteString(q[1 : len(q)-1])
+	}
+	return b.String()
+}
+
+// flatEncoder is a zapcore.Encoder which flattens nested objects and namespaces into dotted keys,
+// e.g. zap.Namespace("http") followed by zap.Int("status", 200) is encoded as "http.status":200.
+// Arrays are passed to the underlying encoder as they are.
+type flatEncoder struct {
+	zapcore.Encoder
+	prefix string
+}
+
+func (e *flatEncoder) Clone() zapcore.Encoder {
+	return &flatEncoder{Encoder: e.Encoder.Clone(), prefix: e.prefix}
+}
+
+func (e *flatEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	c := e.Clone().(*flatEncoder)
+	for _, f := range fields {
+		f.AddTo(c)
+	}
+	return c.Encoder.EncodeEntry(ent, nil)
+}
+
+func (e *flatEncoder) OpenNamespace(key string) {
+	e.prefix += key + "."
+}
+
+func (e *flatEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return obj.MarshalLogObject(&flatEncoder{Encoder: e.Encoder, prefix: e.prefix + key + "."})
+}
+
+func (e *flatEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.Encoder.AddArray(e.prefix+key, arr)
+}
+
+func (e *flatEncoder) AddBinary(key string, value []byte) { e.Encoder.AddBinary(e.prefix+key, value) }
+func (e *flatEncoder) AddByteString(key string, value []byte) {
+	e.Encoder.AddByteString(e.prefix+key, value)
+}
+func (e *flatEncoder) AddBool(key string, value bool) { e.Encoder.AddBool(e.prefix+key, value) }
+func (e *flatEncoder) AddComplex128(key string, value complex128) {
+	e.Encoder.AddComplex128(e.prefix+key, value)
+}
+func (e *flatEncoder) AddComplex64(key string, value complex64) {
+	e.Encoder.AddComplex64(e.prefix+key, value)
+}
+func (e *flatEncoder) AddDuration(key string, value time.Duration) {
+	e.Encoder.AddDuration(e.prefix+key, value)
+}
+func (e *flatEncoder) AddFloat64(key string, value float64) {
+	e.Encoder.AddFloat64(e.prefix+key, value)
+}
+func (e *flatEncoder) AddFloat32(key string, value float32) {
+	e.Encoder.AddFloat32(e.prefix+key, value)
+}
+func (e *flatEncoder) AddInt(key string, value int)        { e.Encoder.AddInt(e.prefix+key, value) }
+func (e *flatEncoder) AddInt64(key string, value int64)    { e.Encoder.AddInt64(e.prefix+key, value) }
+func (e *flatEncoder) AddInt32(key string, value int32)    { e.Encoder.AddInt32(e.prefix+key, value) }
+func (e *flatEncoder) AddInt16(key string, value int16)    { e.Encoder.AddInt16(e.prefix+key, value) }
+func (e *flatEncoder) AddInt8(key string, value int8)      { e.Encoder.AddInt8(e.prefix+key, value) }
+func (e *flatEncoder) AddString(key, value string)         { e.Encoder.AddString(e.prefix+key, value) }
+func (e *flatEncoder) AddTime(key string, value time.Time) { e.Encoder.AddTime(e.prefix+key, value) }
+func (e *flatEncoder) AddUint(key string, value uint)      { e.Encoder.AddUint(e.prefix+key, value) }
+func (e *flatEncoder) AddUint64(key string, value uint64)  { e.Encoder.AddUint64(e.prefix+key, value) }
+func (e *flatEncoder) AddUint32(key string, value uint32)  { e.Encoder.AddUint32(e.prefix+key, value) }
+func (e *flatEncoder) AddUint16(key string, value uint16)  { e.Encoder.AddUint16(e.prefix+key, value) }
+func (e *flatEncoder) AddUint8(key string, value uint8)    { e.Encoder.AddUint8(e.prefix+key, value) }
+func (e *flatEncoder) AddUintptr(key string, value uintptr) {
+	e.Encoder.AddUintptr(e.prefix+key, value)
+}
+
+func (e *flatEncoder) AddReflected(key string, value interface{}) error {
+	return e.Encoder.AddReflected(e.prefix+key, value)
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFlattenNested(t *testing.T) {
+	client := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("ip", "10.0.0.1")
+		return nil
+	})
+
+	for _, flatten := range []bool{false, true} {
+		l, out := newBufferLogger(t, Config{FlattenNested: flatten})
+		l.With(nil, zap.Object("client", client)).Infow("request", zap.Namespace("http"), zap.Int("status", 200))
+
+		entry := out.entries(t)[0]
+		if flatten {
+			if entry["client.ip"] != "10.0.0.1" || entry["http.status"] != float64(200) {
+				t.Errorf("flattened entry = %v, want client.ip and http.status keys", entry)
+			}
+			continue
+		}
+		if http, ok := entry["http"].(map[string]interface{}); !ok || http["status"] != float64(200) {
+			t.Errorf("nested entry = %v, want an http object", entry)
+		}
+	}
+}
+
+// encodeMessage encodes an entry with the message by a console encoder which writes the messages only.
+func encodeMessage(t *testing.T, sanitize, preserveNewlines bool, msg string) string {
+	t.Helper()
+	console := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{MessageKey: "message"})
+	enc := &messageEncoder{Encoder: console, sanitize: sanitize, preserveNewlines: preserveNewlines}
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: msg}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestPreserveNewlines(t *testing.T) {
+	if got, want := encodeMessage(t, true, true, "first\nsecond\n"), "first\n\tsecond\n"; got != want {
+		t.Errorf("encoded %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeControlChars(t *testing.T) {
+	msg := "user\r\nlevel=error\tforged\x1b[31m"
+	if got, want := encodeMessage(t, true, false, msg), `user\r\nlevel=error`+"\tforged"+`\x1b[31m`+"\n"; got != want {
+		t.Errorf("encoded %q, want %q", got, want)
+	}
+	if got, want := encodeMessage(t, false, false, msg), msg+"\n"; got != want {
+		t.Errorf("encoded %q without sanitizing, want %q", got, want)
+	}
+	if got, want := encodeMessage(t, true, true, "a\r\nb"), "a\\r\n\tb\n"; got != want {
+		t.Errorf("encoded %q preserving newlines, want %q", got, want)
+	}
+
+	// the console encoding sanitizes by default
+	l, out := newBufferLogger(t, Config{Encoding: "console"})
+	l.Info("a\nb")
+	if n := strings.Count(out.String(), "\n"); n != 1 {
+		t.Errorf("console output %q has %d lines, want 1", out.String(), n)
+	}
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// encoderSwitch holds the encoder shared by the cores of a logger built by New, which SetEncoding replaces.
+type encoderSwitch struct {
+	mu   sync.Mutex
+	cfg  zap.Config
+	conf Config
+
+	// current is the *switchedEncoder in use
+	current atomic.Value
+}
+
+// switchedEncoder is an encoder set to an encoderSwitch with the version telling the encoders apart.
+type switchedEncoder struct {
+	enc     zapcore.Encoder
+	version uint64
+}
+
+func newEncoderSwitch(cfg zap.Config, conf Config) (*encoderSwitch, error) {
+	enc, err := newEncoder(cfg, conf)
+	if err != nil {
+		return nil, err
+	}
+	s := &encoderSwitch{cfg: cfg, conf: conf}
+	s.current.Store(&switchedEncoder{enc: enc})
+	return s, nil
+}
+
+func (s *encoderSwitch) load() *switchedEncoder {
+	return s.current.Load().(*switchedEncoder)
+}
+
+// set replaces the encoder by the one named by encoding, built with the same config and features.
+func (s *encoderSwitch) set(encoding string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.cfg
+	cfg.Encoding = encoding
+	enc, err := newEncoder(cfg, s.conf)
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	s.current.Store(&switchedEncoder{enc: enc, version: s.load().version + 1})
+	return nil
+}
+
+// switchCore is a zapcore.Core like the one created by zapcore.NewCore enabling all levels, whose encoder
+// follows its encoderSwitch. The encoder with the fields added by With is rebuilt on the first write after
+// the encoder is switched.
+type switchCore struct {
+	sw     *encoderSwitch
+	out    zapcore.WriteSyncer
+	fields []zapcore.Field
+	// enc is the *switchedEncoder of sw with the fields added
+	enc atomic.Value
+}
+
+func newSwitchCore(sw *encoderSwitch, out zapcore.WriteSyncer) *switchCore {
+	return &switchCore{sw: sw, out: out}
+}
+
+func (c *switchCore) Enabled(zapcore.Level) bool {
+	// the entries are filtered by the outermost levelCore
+	return true
+}
+
+func (c *switchCore) With(fields []zapcore.Field) zapcore.Core {
+	return &switchCore{
+		sw:     c.sw,
+		out:    c.out,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *switchCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *switchCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder().EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	_, err = c.out.Write(buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// the process is likely to exit, like zapcore.NewCore does
+		_ = c.out.Sync()
+	}
+	return nil
+}
+
+func (c *switchCore) Sync() error {
+	return c.out.Sync()
+}
+
+// encoder returns the current encoder of the switch with the fields of c added.
+func (c *switchCore) encoder() zapcore.Encoder {
+	current := c.sw.load()
+	if cached, ok := c.enc.Load().(*switchedEncoder); ok && cached.version == current.version {
+		return cached.enc
+	}
+
+	enc := current.enc.Clone()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	c.enc.Store(&switchedEncoder{enc: enc, version: current.version})
+	return enc
+}
+
+// SetEncoding switches the logger built by New and all the loggers derived from it to the encoding, e.g. from
+// "json" to "console" while debugging a running service. The level and the fields added by With are kept.
+func (l *logger) SetEncoding(encoding string) error {
+	if l.encoders == nil {
+		return errors.New("Can not set encoding of a logger not created by New")
+	}
+	return l.encoders.set(encoding)
+}
@@ -0,0 +1,33 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSetEncoding(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	// the fields added by With before the switch are kept
+	wl := l.With(context.Background(), "user", "alice")
+	wl.Info("json")
+	if err := l.SetEncoding("console"); err != nil {
+		t.Fatal(err)
+	}
+	wl.Info("console")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "{") || strings.HasPrefix(lines[1], "{") {
+		t.Fatalf("output = %q, want a JSON line followed by a console line", out.String())
+	}
+	if !strings.Contains(lines[1], "console") || !strings.Contains(lines[1], `{"user": "alice"}`) {
+		t.Errorf("console line = %q, want the message and the fields added by With", lines[1])
+	}
+
+	if err := l.SetEncoding("xml"); err == nil {
+		t.Error("SetEncoding accepted an unknown encoding")
+	}
+	if err := NewByDefault().SetEncoding("console"); err == nil {
+		t.Error("SetEncoding of a logger not created by New succeeded")
+	}
+}
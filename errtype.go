@@ -0,0 +1,37 @@
+package log
+
+import (
+	"net"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithError returns a logger based off l whose messages carry err in the "error" field and its category for
+// grouping and alert routing: the concrete type of the error in "error_type" (like "*net.OpError"), the result
+// of its Code() string method in "error_code" and the result of the Timeout() method of a net.Error in
+// "error_timeout". The category is taken from the cause of an error wrapped by github.com/pkg/errors.
+// The names of the registered sentinels err matches are added as well, see MatchedErrors, and the request
+// and correlation IDs attached to err by AttachContext.
+// l is returned if err is nil.
+func (l *logger) WithError(err error) *logger {
+	if err == nil {
+		return l
+	}
+
+	cause := errors.Cause(err)
+	args := []interface{}{zap.Error(err), "error_type", reflect.TypeOf(cause).String()}
+	if c, ok := cause.(interface{ Code() string }); ok {
+		args = append(args, "error_code", c.Code())
+	}
+	if ne, ok := cause.(net.Error); ok {
+		args = append(args, "error_timeout", ne.Timeout())
+	}
+	if f := MatchedErrors(err); f.Type != zapcore.SkipType {
+		args = append(args, f)
+	}
+	args = append(args, l.attachedFields(err)...)
+	return l.with(args...)
+}
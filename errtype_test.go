@@ -0,0 +1,36 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// codedError is an error with a Code method, which is also a net.Error.
+type codedError struct{}
+
+func (codedError) Error() string   { return "coded" }
+func (codedError) Code() string    { return "E42" }
+func (codedError) Timeout() bool   { return true }
+func (codedError) Temporary() bool { return false }
+
+func TestWithError(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	l.WithError(errors.Wrap(codedError{}, "request failed")).Error("m")
+
+	entry := out.entries(t)[0]
+	for key, want := range map[string]interface{}{
+		"error":         "request failed: coded",
+		"error_type":    "log.codedError",
+		"error_code":    "E42",
+		"error_timeout": true,
+	} {
+		if entry[key] != want {
+			t.Errorf("%s = %v, want %v", key, entry[key], want)
+		}
+	}
+
+	if l.WithError(nil) != l {
+		t.Error("WithError(nil) did not return the logger")
+	}
+}
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Event accumulates the fields of a wide event, a single rich entry summarizing e.g. a whole request.
+// It is not safe for concurrent use.
+type Event struct {
+	logger *logger
+	fields []zap.Field
+}
+
+// Event returns a builder of a wide event logged by l decorated with ctx like by With:
+//
+//	logger.Event(ctx).Str("user", id).Int("items", n).Err(err).Log(zap.InfoLevel, "Order placed")
+func (l *logger) Event(ctx context.Context) *Event {
+	return &Event{logger: l.With(ctx)}
+}
+
+// Str adds a string field.
+func (e *Event) Str(key, val string) *Event {
+	e.fields = append(e.fields, zap.String(key, val))
+	return e
+}
+
+// Int adds an int field.
+func (e *Event) Int(key string, val int) *Event {
+	e.fields = append(e.fields, zap.Int(key, val))
+	return e
+}
+
+// Int64 adds an int64 field.
+func (e *Event) Int64(key string, val int64) *Event {
+	e.fields = append(e.fields, zap.Int64(key, val))
+	return e
+}
+
+// Bool adds a bool field.
+func (e *Event) Bool(key string, val bool) *Event {
+	e.fields = append(e.fields, zap.Bool(key, val))
+	return e
+}
+
+// Dur adds a duration field.
+func (e *Event) Dur(key string, val time.Duration) *Event {
+	e.fields = append(e.fields, zap.Duration(key, val))
+	return e
+}
+
+// Any adds a field of any value, see zap.Any.
+func (e *Event) Any(key string, val interface{}) *Event {
+	e.fields = append(e.fields, zap.Any(key, val))
+	return e
+}
+
+// Err adds the "error" field unless err is nil.
+func (e *Event) Err(err error) *Event {
+	if err != nil {
+		e.fields = append(e.fields, zap.Error(err))
+	}
+	return e
+}
+
+// Log logs the event as a single entry at the level with all the accumulated fields.
+func (e *Event) Log(level zapcore.Level, msg string) {
+	if ce := e.logger.skipCaller().Desugar().Check(level, msg); ce != nil {
+		ce.Write(e.fields...)
+	}
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestEvent(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	l.Event(context.Background()).
+		Str("user", "alice").
+		Int("items", 3).
+		Int64("total", 1500).
+		Bool("express", true).
+		Dur("elapsed", time.Second).
+		Any("tags", []string{"a"}).
+		Err(nil).
+		Log(zap.InfoLevel, "Order placed")
+	l.Event(context.Background()).Err(errors.New("declined")).Log(zap.DebugLevel, "disabled")
+
+	entries := out.entries(t)
+	if len(entries) != 1 {
+		t.Fatalf("entries = %v, want the single INFO event", entries)
+	}
+	e := entries[0]
+	for key, want := range map[string]interface{}{
+		"message": "Order placed",
+		"user":    "alice",
+		"items":   float64(3),
+		"total":   float64(1500),
+		"express": true,
+		"elapsed": float64(time.Second),
+	} {
+		if e[key] != want {
+			t.Errorf("%s = %v, want %v", key, e[key], want)
+		}
+	}
+	if _, ok := e["error"]; ok {
+		t.Error("a nil error is logged")
+	}
+	if caller, _ := e["caller"].(string); !strings.Contains(caller, "event_test.go") {
+		t.Errorf("caller = %q, want the line calling Log", caller)
+	}
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var extractors struct {
+	mu  sync.RWMutex
+	fns []func(ctx context.Context) []zap.Field
+}
+
+// RegisterContextExtractor teaches With how to pull fields out of the application's own context values,
+// e.g. of a request metadata struct stored in the context. The extractors run in the order of registration
+// after the built-in extraction of the request and correlation IDs, for every With call with a non-nil context.
+func RegisterContextExtractor(fn func(ctx context.Context) []zap.Field) {
+	extractors.mu.Lock()
+	defer extractors.mu.Unlock()
+
+	extractors.fns = append(extractors.fns, fn)
+}
+
+// extractedFields returns the fields of all the registered extractors, after the ones they returned for
+// the context detached by DetachContext.
+func extractedFields(ctx context.Context) []interface{} {
+	extractors.mu.RLock()
+	defer extractors.mu.RUnlock()
+
+	var fields []interface{}
+	detached, _ := ctx.Value(extractedFieldsKey).([]interface{})
+	fields = append(fields, detached...)
+	for _, fn := range extractors.fns {
+		for _, f := range fn(ctx) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
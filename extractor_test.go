@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type extractorTestKey struct{}
+
+// extractorTestMeta is the request metadata the test extractor pulls the fields from.
+type extractorTestMeta struct {
+	UserID string
+}
+
+func init() {
+	RegisterContextExtractor(func(ctx context.Context) []zap.Field {
+		meta, ok := ctx.Value(extractorTestKey{}).(*extractorTestMeta)
+		if !ok {
+			return nil
+		}
+		return []zap.Field{zap.String("user_id", meta.UserID)}
+	})
+}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	ctx := context.WithValue(context.Background(), extractorTestKey{}, &extractorTestMeta{UserID: "u1"})
+	l.With(ctx).Info("with")
+	l.With(context.Background()).Info("without")
+
+	entries := out.entries(t)
+	if entries[0]["user_id"] != "u1" {
+		t.Errorf("user_id = %v, want u1", entries[0]["user_id"])
+	}
+	if _, ok := entries[1]["user_id"]; ok {
+		t.Error("user_id added without the context value")
+	}
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// failoverProbeInterval is how often a failed primary output is tried again
+const failoverProbeInterval = time.Second
+
+// failoverSink is a zapcore.WriteSyncer which writes to the fallback after threshold consecutive write errors
+// of the primary, and switches back once a write to the primary succeeds again.
+//
+// A failover and a recovery are reported once to the fallback, the primary is tried at most every failoverProbeInterval
+// while it is failed.
+type failoverSink struct {
+	primary   zapcore.WriteSyncer
+	fallback  zapcore.WriteSyncer
+	threshold int
+
+	mu        sync.Mutex
+	errors    int
+	failed    bool
+	lastProbe time.Time
+}
+
+func newFailoverSink(primary zapcore.WriteSyncer, threshold int) *failoverSink {
+	return &failoverSink{primary: primary, fallback: zapcore.Lock(os.Stderr), threshold: threshold}
+}
+
+func (s *failoverSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed && time.Since(s.lastProbe) < failoverProbeInterval {
+		return s.fallback.Write(p)
+	}
+
+	n, err := s.primary.Write(p)
+	if err == nil {
+		if s.failed {
+			s.failed = false
+			fmt.Fprintf(s.fallback, "%v log: output recovered, switching back from stderr\n", time.Now())
+		}
+		s.errors = 0
+		return n, nil
+	}
+
+	if !s.failed {
+		s.errors++
+		if s.errors < s.threshold {
+			return n, err
+		}
+		s.failed = true
+		fmt.Fprintf(s.fallback, "%v log: failing over to stderr after %d write errors: %v\n", time.Now(), s.errors, err)
+	}
+	s.lastProbe = time.Now()
+	return s.fallback.Write(p)
+}
+
+func (s *failoverSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed {
+		// stderr can not be synced on every platform, so its error is not reported
+		_ = s.fallback.Sync()
+		return nil
+	}
+	return s.primary.Sync()
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakySyncer is a zapcore.WriteSyncer whose writes fail while failing is set.
+type flakySyncer struct {
+	bufferSyncer
+	failing bool
+}
+
+func (s *flakySyncer) Write(p []byte) (int, error) {
+	if s.failing {
+		return 0, errOutput
+	}
+	return s.bufferSyncer.Write(p)
+}
+
+func TestFailoverSink(t *testing.T) {
+	primary, fallback := &flakySyncer{failing: true}, &bufferSyncer{}
+	s := newFailoverSink(primary, 2)
+	s.fallback = fallback
+
+	if _, err := s.Write([]byte("first\n")); err != errOutput {
+		t.Errorf("Write below the threshold = %v, want %v", err, errOutput)
+	}
+	if _, err := s.Write([]byte("second\n")); err != nil {
+		t.Errorf("Write after the failover = %v", err)
+	}
+	if !strings.Contains(fallback.String(), "failing over") || !strings.HasSuffix(fallback.String(), "second\n") {
+		t.Errorf("fallback = %q, want the failover report and the entry", fallback.String())
+	}
+
+	// the primary is not probed before failoverProbeInterval
+	primary.failing = false
+	s.Write([]byte("third\n"))
+	if primary.String() != "" {
+		t.Errorf("primary got %q before the probe interval", primary.String())
+	}
+
+	s.lastProbe = time.Now().Add(-failoverProbeInterval)
+	s.Write([]byte("fourth\n"))
+	if primary.String() != "fourth\n" || !strings.Contains(fallback.String(), "recovered") {
+		t.Errorf("primary = %q, fallback = %q, want the recovery", primary.String(), fallback.String())
+	}
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// fanoutReportInterval is how often the failures of an output are reported to the fallback
+const fanoutReportInterval = 10 * time.Second
+
+// fanoutSink is a zapcore.WriteSyncer which writes to all its outputs independently: a failing output does not
+// keep the entries from the healthy ones. Every failure of an output is reported to onError, and to the fallback
+// at most once per fanoutReportInterval with the number of the failures in between. An error is returned only
+// if all the outputs fail.
+//
+// Unlike zapcore.NewMultiWriteSyncer, which returns the combined error of any failing output, so a single failing
+// output makes zap report every entry as failed.
+type fanoutSink struct {
+	sinks    []zapcore.WriteSyncer
+	names    []string
+	fallback zapcore.WriteSyncer
+	// onError is Config.OnInternalError
+	onError func(error)
+
+	mu sync.Mutex
+	// lastReport is the time of the last report of every output to the fallback, and failures are the failures
+	// of the output not reported since then
+	lastReport []time.Time
+	failures   []int
+}
+
+// newFanoutSink returns a fanoutSink writing to the sinks named by names, reporting the failures to stderr.
+func newFanoutSink(sinks []zapcore.WriteSyncer, names []string) *fanoutSink {
+	return &fanoutSink{
+		sinks:      sinks,
+		names:      names,
+		fallback:   zapcore.Lock(os.Stderr),
+		lastReport: make([]time.Time, len(sinks)),
+		failures:   make([]int, len(sinks)),
+	}
+}
+
+func (s *fanoutSink) Write(p []byte) (int, error) {
+	var lastErr error
+	failed := 0
+	for i, sink := range s.sinks {
+		if _, err := sink.Write(p); err != nil {
+			s.report(i, "write", err)
+			lastErr = err
+			failed++
+		}
+	}
+	if failed > 0 && failed == len(s.sinks) {
+		return 0, lastErr
+	}
+	return len(p), nil
+}
+
+func (s *fanoutSink) Sync() error {
+	var lastErr error
+	failed := 0
+	for i, sink := range s.sinks {
+		if err := sink.Sync(); err != nil {
+			if isConsole(s.names[i]) {
+				// stdout and stderr can not be synced on every platform, so their errors are not reported
+				continue
+			}
+			s.report(i, "sync", err)
+			lastErr = err
+			failed++
+		}
+	}
+	if failed > 0 && failed == len(s.sinks) {
+		return lastErr
+	}
+	return nil
+}
+
+// isConsole tells if the output path is the standard output or the standard error.
+func isConsole(path string) bool {
+	return path == "stdout" || path == "stderr"
+}
+
+func (s *fanoutSink) report(i int, op string, err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[i]++
+	now := time.Now()
+	if now.Sub(s.lastReport[i]) < fanoutReportInterval {
+		return
+	}
+	fmt.Fprintf(s.fallback, "%v log: output %q %s error: %v (%d failures)\n", now, s.names[i], op, err, s.failures[i])
+	s.lastReport[i] = now
+	s.failures[i] = 0
+}
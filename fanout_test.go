@@ -0,0 +1,72 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFanoutSink(t *testing.T) {
+	healthy, fallback := &bufferSyncer{}, &bufferSyncer{}
+	s := newFanoutSink([]zapcore.WriteSyncer{failingSyncer{}, healthy}, []string{"failing", "healthy"})
+	s.fallback = fallback
+	var errs int
+	s.onError = func(error) { errs++ }
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		if _, err := s.Write([]byte("entry\n")); err != nil {
+			t.Fatalf("Write failed while an output is healthy: %v", err)
+		}
+	}
+
+	if got := strings.Count(healthy.String(), "entry\n"); got != n {
+		t.Errorf("the healthy output got %d entries, want %d", got, n)
+	}
+	if errs != n {
+		t.Errorf("onError was called %d times, want %d", errs, n)
+	}
+	if got := strings.Count(fallback.String(), "\n"); got != 1 || !strings.Contains(fallback.String(), `"failing"`) {
+		t.Errorf("fallback = %q, want a single report of the failing output", fallback.String())
+	}
+}
+
+func TestFanoutSinkAllFailing(t *testing.T) {
+	s := newFanoutSink([]zapcore.WriteSyncer{failingSyncer{}, failingSyncer{}}, []string{"a", "b"})
+	s.fallback = &bufferSyncer{}
+	if _, err := s.Write([]byte("entry\n")); err == nil {
+		t.Error("Write succeeded while all the outputs fail")
+	}
+}
+
+func TestFanoutSinkConsoleSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink, closeOut, err := openSinks([]string{"stdout", filepath.Join(dir, "app.log")}, NetworkConfig{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeOut()
+	s := sink.(*fanoutSink)
+	fallback := &bufferSyncer{}
+	s.fallback = fallback
+	var errs []error
+	s.onError = func(err error) { errs = append(errs, err) }
+
+	for i := 0; i < 3; i++ {
+		if err := s.Sync(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(errs) != 0 || fallback.String() != "" {
+		t.Errorf("errors = %v, fallback = %q, want the stdout sync errors ignored", errs, fallback.String())
+	}
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldLimitEncoder is a zapcore.Encoder which truncates the string values longer than max bytes, including
+// the values in nested objects, marking them by the number of the bytes cut off.
+type fieldLimitEncoder struct {
+	fieldLimitObject
+	enc zapcore.Encoder
+}
+
+func newFieldLimitEncoder(enc zapcore.Encoder, max int) *fieldLimitEncoder {
+	return &fieldLimitEncoder{fieldLimitObject: fieldLimitObject{ObjectEncoder: enc, max: max}, enc: enc}
+}
+
+func (e *fieldLimitEncoder) Clone() zapcore.Encoder {
+	return newFieldLimitEncoder(e.enc.Clone(), e.max)
+}
+
+func (e *fieldLimitEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	c := e.Clone().(*fieldLimitEncoder)
+	for _, f := range fields {
+		f.AddTo(c)
+	}
+	return c.enc.EncodeEntry(ent, nil)
+}
+
+// fieldLimitObject is a zapcore.ObjectEncoder which truncates the string values longer than max bytes.
+type fieldLimitObject struct {
+	zapcore.ObjectEncoder
+	max int
+}
+
+func (e *fieldLimitObject) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.ObjectEncoder.AddObject(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		return obj.MarshalLogObject(&fieldLimitObject{ObjectEncoder: enc, max: e.max})
+	}))
+}
+
+func (e *fieldLimitObject) AddString(key, value string) {
+	e.ObjectEncoder.AddString(key, truncateCounting(value, e.max))
+}
+
+func (e *fieldLimitObject) AddByteString(key string, value []byte) {
+	if len(value) > e.max {
+		e.ObjectEncoder.AddString(key, truncateCounting(string(value), e.max))
+		return
+	}
+	e.ObjectEncoder.AddByteString(key, value)
+}
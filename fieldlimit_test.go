@@ -0,0 +1,33 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMaxFieldBytes(t *testing.T) {
+	l, out := newBufferLogger(t, Config{MaxFieldBytes: 4})
+	nested := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("body", "0123456789")
+		return nil
+	})
+	l.With(context.Background(), zap.Object("req", nested)).Infow("a message longer than the limit",
+		"long", "0123456789",
+		"short", "abc",
+	)
+
+	entry := out.entries(t)[0]
+	want := "0123…[truncated 6 bytes]"
+	if entry["long"] != want || entry["short"] != "abc" {
+		t.Errorf("long = %q, short = %q, want %q and abc", entry["long"], entry["short"], want)
+	}
+	if req, _ := entry["req"].(map[string]interface{}); req["body"] != want {
+		t.Errorf("req.body = %v, want %q", req["body"], want)
+	}
+	if entry["message"] != "a message longer than the limit" {
+		t.Errorf("message = %q, want it untouched", entry["message"])
+	}
+}
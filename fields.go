@@ -0,0 +1,32 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Object constructs a field which is encoded by the MarshalLogObject method of val. It may be passed to With
+// along with the name, value pairs.
+//
+// Marshaling complex structs this way is much faster than passing them as plain values, which are encoded
+// by reflection.
+func Object(key string, val zapcore.ObjectMarshaler) zap.Field {
+	return zap.Object(key, val)
+}
+
+// StringNonEmpty constructs a string field, or a no-op field if val is empty, so an optional value
+// may be passed to With without a condition.
+func StringNonEmpty(key, val string) zap.Field {
+	if val == "" {
+		return zap.Skip()
+	}
+	return zap.String(key, val)
+}
+
+// IntNonZero constructs an int field, or a no-op field if val is zero, like StringNonEmpty.
+func IntNonZero(key string, val int) zap.Field {
+	if val == 0 {
+		return zap.Skip()
+	}
+	return zap.Int(key, val)
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// user is a zapcore.ObjectMarshaler.
+type user struct {
+	name string
+	age  int
+}
+
+func (u user) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	enc.AddInt("age", u.age)
+	return nil
+}
+
+func TestObject(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	l.With(context.Background(), Object("user", user{name: "alice", age: 30}), "id", 1).Info("m")
+
+	entry := out.entries(t)[0]
+	want := map[string]interface{}{"name": "alice", "age": float64(30)}
+	if !reflect.DeepEqual(entry["user"], want) || entry["id"] != float64(1) {
+		t.Errorf("entry = %v, want user %v and id 1", entry, want)
+	}
+}
+
+func TestNonEmptyFields(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	l.With(context.Background(),
+		StringNonEmpty("name", "alice"),
+		StringNonEmpty("nickname", ""),
+		IntNonZero("age", 30),
+		IntNonZero("children", 0),
+	).Info("m")
+
+	entry := out.entries(t)[0]
+	if entry["name"] != "alice" || entry["age"] != float64(30) {
+		t.Errorf("entry = %v, want the non-empty fields", entry)
+	}
+	for _, key := range []string{"nickname", "children"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("the empty %s field is logged", key)
+		}
+	}
+}
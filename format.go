@@ -0,0 +1,102 @@
+package log
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Debugf uses fmt.Sprintf to construct and log a message at DEBUG level.
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.checkFormat(format, args)
+	l.skipCaller().Debugf(format, args...)
+}
+
+// Infof uses fmt.Sprintf to construct and log a message at INFO level.
+func (l *logger) Infof(format string, args ...interface{}) {
+	l.checkFormat(format, args)
+	l.skipCaller().Infof(format, args...)
+}
+
+// Warnf uses fmt.Sprintf to construct and log a message at WARN level.
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.checkFormat(format, args)
+	l.skipCaller().Warnf(format, args...)
+}
+
+// Errorf uses fmt.Sprintf to construct and log a message at ERROR level.
+func (l *logger) Errorf(format string, args ...interface{}) {
+	l.checkFormat(format, args)
+	l.skipCaller().Errorf(format, args...)
+}
+
+// logf logs a message constructed by fmt.Sprintf at the level, or the format itself if there are no args,
+// like the f-methods of zap.SugaredLogger. It must be called directly by the method called by the user.
+func (l *logger) logf(lvl zapcore.Level, format string, args []interface{}) {
+	zl := l.SugaredLogger.Desugar()
+	if !zl.Core().Enabled(lvl) {
+		return
+	}
+
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	if ce := zl.WithOptions(zap.AddCallerSkip(2)).Check(lvl, msg); ce != nil {
+		ce.Write()
+	}
+}
+
+// checkFormat logs a DPanic entry if Config.StrictFormat is set and the number of the args does not match
+// the verbs of the format, like in Infof("50% done"). It must be called directly by the method called by the user.
+func (l *logger) checkFormat(format string, args []interface{}) {
+	if !l.strictFormat {
+		return
+	}
+	want, ok := formatArgs(format)
+	if ok && want == len(args) {
+		return
+	}
+	l.SugaredLogger.Desugar().WithOptions(zap.AddCallerSkip(2)).DPanic("Format arguments mismatch",
+		zap.String("format", format),
+		zap.Int("args", len(args)),
+		zap.Int("verbs", want),
+	)
+}
+
+// formatArgs returns the number of the args the fmt format consumes. It is not ok if the format is malformed,
+// i.e. it ends with a lone '%'. The escaped "%%" consumes no args. The number is -1 if the format uses explicit
+// argument indexes like "%[1]d", which are not checked.
+func formatArgs(format string) (n int, ok bool) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		// flags
+		for i < len(format) && (format[i] == '+' || format[i] == '-' || format[i] == '#' || format[i] == ' ' || format[i] == '0') {
+			i++
+		}
+		if i < len(format) && format[i] == '[' {
+			return -1, true
+		}
+		// width and precision, a '*' consumes an arg
+		for i < len(format) && (format[i] == '*' || format[i] == '.' || ('0' <= format[i] && format[i] <= '9')) {
+			if format[i] == '*' {
+				n++
+			}
+			i++
+		}
+		if i < len(format) && format[i] == '[' {
+			return -1, true
+		}
+		if i == len(format) {
+			return n, false
+		}
+		if format[i] != '%' {
+			n++
+		}
+	}
+	return n, true
+}
@@ -0,0 +1,99 @@
+package log
+
+import "testing"
+
+// countingStringer counts the calls of its String method.
+type countingStringer struct {
+	calls int
+}
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return "value"
+}
+
+func TestFormatSkippedWhenDisabled(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info"})
+	s := &countingStringer{}
+
+	l.Debugf("debug %v", s)
+	l.Debug(s)
+	if s.calls != 0 {
+		t.Errorf("String called %d times while DEBUG is disabled", s.calls)
+	}
+
+	l.Infof("info %v", s)
+	if s.calls != 1 {
+		t.Errorf("String called %d times for an enabled entry, want 1", s.calls)
+	}
+	if entries := out.entries(t); len(entries) != 1 || entries[0]["message"] != "info value" {
+		t.Errorf("entries = %v, want the INFO entry only", entries)
+	}
+}
+
+func TestStrictFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		n      int
+		ok     bool
+	}{
+		{"plain", 0, true},
+		{"%d of %s", 2, true},
+		{"100%% done", 0, true},
+		{"%+v %-5d %08.3f", 3, true},
+		{"%[1]d", -1, true},
+		{"50%", 0, false},
+	} {
+		n, ok := formatArgs(tt.format)
+		if n != tt.n || ok != tt.ok {
+			t.Errorf("formatArgs(%q) = %d, %v, want %d, %v", tt.format, n, ok, tt.n, tt.ok)
+		}
+	}
+
+	l, out := newBufferLogger(t, Config{StrictFormat: true})
+	l.Infof("%d done", 1)
+	l.Infof("%d of %d done", 1)
+	entries := out.entries(t)
+	if len(entries) != 3 || entries[1]["level"] != "dpanic" || entries[1]["verbs"] != float64(2) || entries[1]["args"] != float64(1) {
+		t.Errorf("entries = %v, want a DPANIC entry reporting the mismatch before the second message", entries)
+	}
+}
+
+func TestPrintLevel(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	l.Print("default")
+	if out.String() != "" {
+		t.Errorf("Print logged at INFO by default: %s", out.String())
+	}
+
+	l, out = newBufferLogger(t, Config{PrintLevel: "warn"})
+	l.Print("print")
+	l.Printf("printf %d", 1)
+	entries := out.entries(t)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %v, want 2", entries)
+	}
+	for _, entry := range entries {
+		if entry["level"] != "warn" {
+			t.Errorf("%q logged at %v, want warn", entry["message"], entry["level"])
+		}
+	}
+
+	if _, err := New(Config{Encoding: "json", PrintLevel: "verbose"}); err == nil {
+		t.Error("New accepted an invalid print level")
+	}
+}
+
+func BenchmarkDebugfDisabled(b *testing.B) {
+	l, _ := newBufferLogger(b, Config{Level: "info"})
+	s := &countingStringer{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("debug %v", s)
+	}
+	b.StopTimer()
+	if s.calls != 0 {
+		b.Errorf("String called %d times while DEBUG is disabled", s.calls)
+	}
+}
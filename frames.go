@@ -0,0 +1,64 @@
+package log
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// Frames constructs a "frames" field with the stack trace recorded by github.com/pkg/errors in err as an array
+// of {file, line, func} objects, which can be queried unlike the stack in the "errorVerbose" string.
+// The deepest stack trace in the chain of causes is used, as it is the closest to the origin of the error.
+// The field is skipped if err carries no stack trace.
+func Frames(err error) zap.Field {
+	var st errors.StackTrace
+	for err != nil {
+		if tracer, ok := err.(stackTracer); ok {
+			st = tracer.StackTrace()
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+	if st == nil {
+		return zap.Skip()
+	}
+	return zap.Array("frames", frames(st))
+}
+
+type frames errors.StackTrace
+
+func (fs frames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, f := range fs {
+		if err := enc.AppendObject(frame(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type frame errors.Frame
+
+func (f frame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	// errors.Frame is the return address, the call is at the preceding instruction
+	pc := uintptr(f) - 1
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		enc.AddString("file", "unknown")
+		enc.AddString("func", "unknown")
+		return nil
+	}
+	file, line := fn.FileLine(pc)
+	enc.AddString("file", file)
+	enc.AddInt("line", line)
+	enc.AddString("func", fn.Name())
+	return nil
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+func newFramesError() error {
+	return errors.New("origin")
+}
+
+func TestFrames(t *testing.T) {
+	err := errors.Wrap(newFramesError(), "wrapped")
+	f := Frames(err)
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	frames, ok := enc.Fields["frames"].([]interface{})
+	if !ok || len(frames) == 0 {
+		t.Fatalf("frames = %v, want an array of frames", enc.Fields["frames"])
+	}
+	first := frames[0].(map[string]interface{})
+	if fn, _ := first["func"].(string); !strings.HasSuffix(fn, ".newFramesError") {
+		t.Errorf("first frame func = %v, want the origin of the error", first["func"])
+	}
+	if file, _ := first["file"].(string); !strings.HasSuffix(file, "frames_test.go") || first["line"] == 0 {
+		t.Errorf("first frame = %v, want the line in frames_test.go", first)
+	}
+
+	if f := Frames(fmt.Errorf("plain")); f.Type != zapcore.SkipType {
+		t.Error("Frames of an error without a stack trace is not skipped")
+	}
+	if f := Frames(nil); f.Type != zapcore.SkipType {
+		t.Error("Frames(nil) is not skipped")
+	}
+}
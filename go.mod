@@ -6,6 +6,8 @@ require (
 	github.com/go-ozzo/ozzo-routing/v2 v2.3.0
 	github.com/google/uuid v1.2.0
 	github.com/pkg/errors v0.9.1
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
 	go.uber.org/zap v1.16.0
 	gorm.io/gorm v1.25.1
 )
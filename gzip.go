@@ -0,0 +1,133 @@
+package log
+
+import (
+	"compress/gzip"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	gzipScheme = "gzip"
+	// gzipFlushInterval is how often buffered compressed data is flushed to the file
+	gzipFlushInterval = time.Second
+)
+
+func init() {
+	if err := zap.RegisterSink(gzipScheme, newGzipSink); err != nil {
+		panic(err)
+	}
+}
+
+// gzipPaths rewrites the file paths among paths to the gzip sink scheme.
+func gzipPaths(paths []string) []string {
+	res := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path == "stdout" || path == "stderr" {
+			res = append(res, path)
+			continue
+		}
+		if u, err := url.Parse(path); err == nil && u.Scheme != "" {
+			if u.Scheme != "file" {
+				res = append(res, path)
+				continue
+			}
+			path = u.Path
+		}
+		res = append(res, gzipScheme+":"+path)
+	}
+	return res
+}
+
+// gzipSink is a zap.Sink which compresses everything written to a file.
+//
+// Compressed data is flushed to the file by a background goroutine at most gzipFlushInterval after it was written
+// and on every Sync, so the file is always a valid (possibly unterminated) gzip stream. Close, called by the Close
+// of the logger, stops the goroutine and writes the gzip trailer.
+type gzipSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	gz     *gzip.Writer
+	dirty  bool
+	closed bool
+	stop   chan struct{}
+}
+
+func newGzipSink(u *url.URL) (zap.Sink, error) {
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	s := &gzipSink{
+		file: file,
+		gz:   gzip.NewWriter(file),
+		stop: make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *gzipSink) run() {
+	ticker := time.NewTicker(gzipFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.dirty && !s.closed {
+				s.dirty = false
+				_ = s.gz.Flush()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *gzipSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dirty = true
+	return s.gz.Write(p)
+}
+
+func (s *gzipSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.dirty = false
+	if err := s.gz.Flush(); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *gzipSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.stop)
+	if err := s.gz.Close(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
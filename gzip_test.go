@@ -0,0 +1,74 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGzipOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.log.gz")
+
+	l, err := New(Config{Encoding: "json", OutputPaths: []string{path}, GzipOutput: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("first")
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stream is not terminated before Close, but the synced entries can be read
+	data := gunzip(t, path, true)
+	if !bytes.Contains(data, []byte(`"message":"first"`)) {
+		t.Errorf("synced entry missing in %q", data)
+	}
+
+	l.Info("second")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data = gunzip(t, path, false)
+	if !bytes.Contains(data, []byte(`"message":"first"`)) || !bytes.Contains(data, []byte(`"message":"second"`)) {
+		t.Errorf("entries missing in %q", data)
+	}
+}
+
+// gunzip decompresses the file, which may lack the gzip trailer if unterminated is set.
+func gunzip(t *testing.T, path string, unterminated bool) []byte {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil && !(unterminated && err == io.ErrUnexpectedEOF) {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGzipPaths(t *testing.T) {
+	got := gzipPaths([]string{"stderr", "/var/log/app.log.gz", "file:///tmp/a.gz", "tcp://host:1"})
+	want := []string{"stderr", "gzip:/var/log/app.log.gz", "gzip:/tmp/a.gz", "tcp://host:1"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("gzipPaths()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithHooks returns a logger based off l which calls the hooks with every entry it writes, like zap.Hooks does.
+func (l *logger) WithHooks(hooks ...func(zapcore.Entry) error) *logger {
+	nl := *l
+	nl.setSugar(l.SugaredLogger.Desugar().WithOptions(zap.Hooks(hooks...)).Sugar())
+	return &nl
+}
@@ -0,0 +1,19 @@
+package log
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	hostnameOnce sync.Once
+	hostnameVal  string
+)
+
+// hostname returns os.Hostname() looked up once per process, or "" if it is unknown.
+func hostname() string {
+	hostnameOnce.Do(func() {
+		hostnameVal, _ = os.Hostname()
+	})
+	return hostnameVal
+}
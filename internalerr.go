@@ -0,0 +1,25 @@
+package log
+
+import "go.uber.org/zap/zapcore"
+
+// errorHookSink is a zapcore.WriteSyncer which reports the errors of the writes and syncs of its output to a hook.
+type errorHookSink struct {
+	zapcore.WriteSyncer
+	hook func(error)
+}
+
+func (s *errorHookSink) Write(p []byte) (int, error) {
+	n, err := s.WriteSyncer.Write(p)
+	if err != nil {
+		s.hook(err)
+	}
+	return n, err
+}
+
+func (s *errorHookSink) Sync() error {
+	err := s.WriteSyncer.Sync()
+	if err != nil {
+		s.hook(err)
+	}
+	return err
+}
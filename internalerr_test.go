@@ -0,0 +1,34 @@
+package log
+
+import (
+	"errors"
+	"testing"
+)
+
+// failingSyncer is a zapcore.WriteSyncer whose writes and syncs fail.
+type failingSyncer struct{}
+
+var errOutput = errors.New("disk full")
+
+func (failingSyncer) Write(p []byte) (int, error) { return 0, errOutput }
+func (failingSyncer) Sync() error                 { return errOutput }
+
+func TestOnInternalError(t *testing.T) {
+	var errs []error
+	l, err := NewWithSyncer(failingSyncer{}, Config{Encoding: "json", OnInternalError: func(err error) {
+		errs = append(errs, err)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs = nil
+
+	l.Info("lost")
+	if len(errs) != 1 || errs[0] != errOutput {
+		t.Fatalf("OnInternalError got %v after a failed write, want [%v]", errs, errOutput)
+	}
+	l.Sync()
+	if len(errs) != 2 {
+		t.Errorf("OnInternalError got %v after a failed sync, want 2 errors", errs)
+	}
+}
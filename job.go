@@ -0,0 +1,13 @@
+package log
+
+import "context"
+
+// ForJob returns a context and a logger for a run of a background job, like a cron task or a queue consumer,
+// which has no request to take the IDs from. Every call generates a fresh run ID by NewID, which is the
+// correlation ID of the context. The logger, also stored in the context for FromContext, adds the "job"
+// and "job_run_id" fields.
+func (l *logger) ForJob(jobName string) (context.Context, *logger) {
+	id := NewID()
+	ctx := context.WithValue(context.Background(), correlationIDKey, id)
+	return l.WithContext(ctx, "job", jobName, "job_run_id", id)
+}
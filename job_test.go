@@ -0,0 +1,21 @@
+package log
+
+import "testing"
+
+func TestForJob(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	ctx1, jl := l.ForJob("cleanup")
+	ctx2, _ := l.ForJob("cleanup")
+	if CorrelationID(ctx1) == "" || CorrelationID(ctx1) == CorrelationID(ctx2) {
+		t.Errorf("run IDs %q and %q, want two fresh IDs", CorrelationID(ctx1), CorrelationID(ctx2))
+	}
+	if FromContext(ctx1) != jl {
+		t.Error("the job logger is not stored in the context")
+	}
+
+	jl.Info("m")
+	entry := out.entries(t)[0]
+	if entry["job"] != "cleanup" || entry["job_run_id"] != CorrelationID(ctx1) || entry["CorrelationID"] != CorrelationID(ctx1) {
+		t.Errorf("entry = %v, want the job, its run ID and the correlation ID", entry)
+	}
+}
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// keyCaseConverter returns the function converting the field keys to the case named by Config.KeyCase,
+// or nil if the keys are kept as they are.
+func keyCaseConverter(keyCase string) (func(string) string, error) {
+	switch keyCase {
+	case "", "none":
+		return nil, nil
+	case "snake":
+		return snakeCase, nil
+	case "camel":
+		return camelCase, nil
+	default:
+		return nil, errors.Errorf("Unknown key case %q, expected snake, camel or none", keyCase)
+	}
+}
+
+// snakeCase converts a camelCase or PascalCase key to snake_case keeping acronyms together,
+// e.g. "HTTPStatus" to "http_status" and "userID" to "user_id".
+func snakeCase(key string) string {
+	rs := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			if i > 0 && rs[i-1] != '_' && rs[i-1] != '.' &&
+				(!unicode.IsUpper(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// camelCase converts a snake_case key to camelCase, e.g. "user_id" to "userId". Leading underscores are kept.
+func camelCase(key string) string {
+	rs := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key))
+	upper := false
+	for i, r := range rs {
+		if r == '_' && i > 0 && rs[i-1] != '_' && rs[i-1] != '.' && i+1 < len(rs) && rs[i+1] != '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// keyCaseEncoder is a zapcore.Encoder which converts the keys of the fields, including the keys in nested objects
+// and namespaces. The keys of the objects in arrays and the keys of the entry itself given by the encoder config
+// are kept as they are.
+type keyCaseEncoder struct {
+	keyCaseObject
+	enc zapcore.Encoder
+}
+
+func newKeyCaseEncoder(enc zapcore.Encoder, conv func(string) string) *keyCaseEncoder {
+	return &keyCaseEncoder{keyCaseObject: keyCaseObject{ObjectEncoder: enc, conv: conv}, enc: enc}
+}
+
+func (e *keyCaseEncoder) Clone() zapcore.Encoder {
+	return newKeyCaseEncoder(e.enc.Clone(), e.conv)
+}
+
+func (e *keyCaseEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	c := e.Clone().(*keyCaseEncoder)
+	for _, f := range fields {
+		f.AddTo(c)
+	}
+	return c.enc.EncodeEntry(ent, nil)
+}
+
+// keyCaseObject is a zapcore.ObjectEncoder which converts the keys by conv.
+type keyCaseObject struct {
+	zapcore.ObjectEncoder
+	conv func(string) string
+}
+
+func (e *keyCaseObject) OpenNamespace(key string) {
+	e.ObjectEncoder.OpenNamespace(e.conv(key))
+}
+
+func (e *keyCaseObject) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.ObjectEncoder.AddObject(e.conv(key), zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		return obj.MarshalLogObject(&keyCaseObject{ObjectEncoder: enc, conv: e.conv})
+	}))
+}
+
+func (e *keyCaseObject) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.ObjectEncoder.AddArray(e.conv(key), arr)
+}
+
+func (e *keyCaseObject) AddBinary(key string, value []byte) {
+	e.ObjectEncoder.AddBinary(e.conv(key), value)
+}
+func (e *keyCaseObject) AddByteString(key string, value []byte) {
+	e.ObjectEncoder.AddByteString(e.conv(key), value)
+}
+func (e *keyCaseObject) AddBool(key string, value bool) { e.ObjectEncoder.AddBool(e.conv(key), value) }
+func (e *keyCaseObject) AddComplex128(key string, value complex128) {
+	e.ObjectEncoder.AddComplex128(e.conv(key), value)
+}
+func (e *keyCaseObject) AddComplex64(key string, value complex64) {
+	e.ObjectEncoder.AddComplex64(e.conv(key), value)
+}
+func (e *keyCaseObject) AddDuration(key string, value time.Duration) {
+	e.ObjectEncoder.AddDuration(e.conv(key), value)
+}
+func (e *keyCaseObject) AddFloat64(key string, value float64) {
+	e.ObjectEncoder.AddFloat64(e.conv(key), value)
+}
+func (e *keyCaseObject) AddFloat32(key string, value float32) {
+	e.ObjectEncoder.AddFloat32(e.conv(key), value)
+}
+func (e *keyCaseObject) AddInt(key string, value int) { e.ObjectEncoder.AddInt(e.conv(key), value) }
+func (e *keyCaseObject) AddInt64(key string, value int64) {
+	e.ObjectEncoder.AddInt64(e.conv(key), value)
+}
+func (e *keyCaseObject) AddInt32(key string, value int32) {
+	e.ObjectEncoder.AddInt32(e.conv(key), value)
+}
+func (e *keyCaseObject) AddInt16(key string, value int16) {
+	e.ObjectEncoder.AddInt16(e.conv(key), value)
+}
+func (e *keyCaseObject) AddInt8(key string, value int8) { e.ObjectEncoder.AddInt8(e.conv(key), value) }
+func (e *keyCaseObject) AddString(key, value string)    { e.ObjectEncoder.AddString(e.conv(key), value) }
+func (e *keyCaseObject) AddTime(key string, value time.Time) {
+	e.ObjectEncoder.AddTime(e.conv(key), value)
+}
+func (e *keyCaseObject) AddUint(key string, value uint) { e.ObjectEncoder.AddUint(e.conv(key), value) }
+func (e *keyCaseObject) AddUint64(key string, value uint64) {
+	e.ObjectEncoder.AddUint64(e.conv(key), value)
+}
+func (e *keyCaseObject) AddUint32(key string, value uint32) {
+	e.ObjectEncoder.AddUint32(e.conv(key), value)
+}
+func (e *keyCaseObject) AddUint16(key string, value uint16) {
+	e.ObjectEncoder.AddUint16(e.conv(key), value)
+}
+func (e *keyCaseObject) AddUint8(key string, value uint8) {
+	e.ObjectEncoder.AddUint8(e.conv(key), value)
+}
+func (e *keyCaseObject) AddUintptr(key string, value uintptr) {
+	e.ObjectEncoder.AddUintptr(e.conv(key), value)
+}
+
+func (e *keyCaseObject) AddReflected(key string, value interface{}) error {
+	return e.ObjectEncoder.AddReflected(e.conv(key), value)
+}
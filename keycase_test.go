@@ -0,0 +1,54 @@
+package log
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for key, want := range map[string]string{
+		"HTTPStatus": "http_status",
+		"userID":     "user_id",
+		"RequestID":  "request_id",
+		"already_ok": "already_ok",
+	} {
+		if got := snakeCase(key); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	for key, want := range map[string]string{
+		"user_id":     "userId",
+		"_private":    "_private",
+		"http.status": "http.status",
+		"a__b":        "a__b",
+	} {
+		if got := camelCase(key); got != want {
+			t.Errorf("camelCase(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestKeyCase(t *testing.T) {
+	l, out := newBufferLogger(t, Config{KeyCase: "snake"})
+	l.Infow("m", "HTTPStatus", 200, zap.Object("ClientInfo", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("RemoteAddr", "10.0.0.1")
+		return nil
+	})))
+
+	entry := out.entries(t)[0]
+	if entry["http_status"] != float64(200) || entry["message"] != "m" {
+		t.Errorf("entry = %v, want the http_status key", entry)
+	}
+	if client, ok := entry["client_info"].(map[string]interface{}); !ok || client["remote_addr"] != "10.0.0.1" {
+		t.Errorf("client_info = %v, want the nested remote_addr key", entry["client_info"])
+	}
+
+	if _, err := New(Config{Encoding: "json", KeyCase: "kebab"}); err == nil {
+		t.Error("New accepted an unknown key case")
+	}
+}
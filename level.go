@@ -0,0 +1,209 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// offLevel is above all the zapcore levels, so a logger at offLevel writes no entries at all.
+const offLevel = zapcore.FatalLevel + 1
+
+// parseLevel parses one of the zapcore level names, a level registered by RegisterLevel or "off" (alias "silent"),
+// which disables all output.
+func parseLevel(text string) (zapcore.Level, error) {
+	switch text {
+	case "off", "silent":
+		return offLevel, nil
+	}
+	if lvl, ok := customLevel(text); ok {
+		return lvl, nil
+	}
+
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(text)); err != nil {
+		return lvl, errors.Wrapf(err, "Can not unmarshal text %q, expected one of zapcore.Levels or \"off\"", text)
+	}
+	return lvl, nil
+}
+
+// parseLevels parses the level names like parseLevel into a set.
+func parseLevels(names []string) (map[zapcore.Level]bool, error) {
+	set := make(map[zapcore.Level]bool, len(names))
+	for _, name := range names {
+		lvl, err := parseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		set[lvl] = true
+	}
+	return set, nil
+}
+
+// levelName is the name of the level as accepted by parseLevel.
+func levelName(lvl zapcore.Level) string {
+	if lvl >= offLevel {
+		return "off"
+	}
+	if name, ok := customLevelName(lvl); ok {
+		return name
+	}
+	return lvl.String()
+}
+
+// SetLevel changes the minimal enabled level of the logger and of all loggers derived from it.
+//
+// Unless Config.DisableLevelAudit is set, the change is recorded by a "logger.level.changed" info entry
+// with the "old_level" and "new_level" fields. The entry is written while the more verbose of the two
+// levels is in effect.
+func (l *logger) SetLevel(level string) error {
+	return l.setLevel(level)
+}
+
+func (l *logger) setLevel(level string, args ...interface{}) error {
+	if l.level == (zap.AtomicLevel{}) {
+		return errors.New("Can not set level of a logger which was not created by New")
+	}
+
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	old := l.level.Level()
+	if lvl > old {
+		l.auditLevel(old, lvl, args)
+		l.level.SetLevel(lvl)
+	} else {
+		l.level.SetLevel(lvl)
+		l.auditLevel(old, lvl, args)
+	}
+	return nil
+}
+
+func (l *logger) auditLevel(from, to zapcore.Level, args []interface{}) {
+	if l.disableLevelAudit {
+		return
+	}
+	args = append(args, "old_level", levelName(from), "new_level", levelName(to))
+	l.Infow("logger.level.changed", args...)
+}
+
+// WithLevel returns a logger based off l whose minimal enabled level is pinned to level, regardless of
+// later SetLevel calls. It lets a component log at DEBUG while the rest of the application stays at INFO.
+//
+// An invalid level is reported by a DPANIC entry and l is returned as it is.
+func (l *logger) WithLevel(level string) *logger {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		l.skipCaller().DPanic(err)
+		return l
+	}
+	return l.withLevelEnabler(lvl)
+}
+
+// Verbose calls fn with a logger based off l which logs at DEBUG level regardless of the level of l, e.g. to trace
+// a single operation. The loggers used by other code, including other goroutines, are not affected.
+func (l *logger) Verbose(fn func(Logger)) {
+	fn(l.withLevelEnabler(zapcore.DebugLevel))
+}
+
+// withLevelEnabler returns a logger like l whose entries are filtered by enab instead of the logger level.
+//
+// The cores built by New enable all levels and leave the filtering to the outermost levelCore, which is
+// replaced here. Other cores can not be made more verbose than they are.
+func (l *logger) withLevelEnabler(enab zapcore.LevelEnabler) *logger {
+	zl := l.SugaredLogger.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		var mirror *levelCore
+		if lc, ok := core.(*levelCore); ok {
+			core, mirror = lc.Core, lc.mirror
+		}
+		return &levelCore{Core: core, enab: enab, mirror: mirror}
+	}))
+
+	nl := *l
+	nl.setSugar(zl.Sugar())
+	return &nl
+}
+
+// levelCore is a zapcore.Core which filters the entries by a LevelEnabler before checking them with its core.
+// The mirror core, if any, gets the entries regardless of the level; it is a levelCore itself, so the check
+// of a level disabled by enab stays as cheap as the check of the mirror level.
+type levelCore struct {
+	zapcore.Core
+	enab   zapcore.LevelEnabler
+	mirror *levelCore
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.enab.Enabled(lvl) || c.mirror != nil && c.mirror.Enabled(lvl)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	nc := &levelCore{Core: c.Core.With(fields), enab: c.enab}
+	if c.mirror != nil {
+		nc.mirror = c.mirror.With(fields).(*levelCore)
+	}
+	return nc
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.enab.Enabled(ent.Level) {
+		ce = c.Core.Check(ent, ce)
+	}
+	if c.mirror != nil && c.mirror.Enabled(ent.Level) {
+		ce = c.mirror.Check(ent, ce)
+	}
+	return ce
+}
+
+func (c *levelCore) Sync() error {
+	err := c.Core.Sync()
+	if c.mirror != nil {
+		if mErr := c.mirror.Sync(); err == nil {
+			err = mErr
+		}
+	}
+	return err
+}
+
+// LevelHandler returns an HTTP handler which reports the current level on GET and changes it on PUT.
+//
+// PUT requests expect a payload like {"level":"debug"}; the audit entry of such a change also
+// records the "remote_addr" of the request.
+func (l *logger) LevelHandler() http.Handler {
+	type payload struct {
+		Level string `json:"level,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+
+		switch r.Method {
+		case http.MethodGet:
+			enc.Encode(payload{Level: levelName(l.level.Level())})
+
+		case http.MethodPut:
+			var req payload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				enc.Encode(payload{Error: "Request body must be well-formed JSON: " + err.Error()})
+				return
+			}
+			if err := l.setLevel(req.Level, "remote_addr", r.RemoteAddr); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				enc.Encode(payload{Error: err.Error()})
+				return
+			}
+			enc.Encode(payload{Level: req.Level})
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			enc.Encode(payload{Error: "Only GET and PUT are supported."})
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetLevelAudit(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info"})
+	if err := l.SetLevel("error"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := out.entries(t)
+	if len(entries) != 2 {
+		t.Fatalf("%d audit entries, want 2: %v", len(entries), entries)
+	}
+	for i, want := range [][2]string{{"info", "error"}, {"error", "debug"}} {
+		e := entries[i]
+		if e["message"] != "logger.level.changed" || e["old_level"] != want[0] || e["new_level"] != want[1] {
+			t.Errorf("audit entry %d = %v, want %s -> %s", i, e, want[0], want[1])
+		}
+	}
+
+	if err := l.SetLevel("verbose"); err == nil {
+		t.Error("SetLevel accepted an invalid level")
+	}
+}
+
+func TestDisableLevelAudit(t *testing.T) {
+	l, out := newBufferLogger(t, Config{DisableLevelAudit: true})
+	if err := l.SetLevel("warn"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "" {
+		t.Errorf("audit entry logged while disabled: %s", out.String())
+	}
+}
+
+func TestSetLevelNotNew(t *testing.T) {
+	if err := NewByDefault().SetLevel("debug"); err == nil {
+		t.Error("SetLevel of a logger not created by New succeeded")
+	}
+}
+
+func TestDeadlineDebug(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info", DeadlineDebugThreshold: time.Minute})
+
+	near, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	far, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	l.With(near).Debug("near")
+	l.With(far).Debug("far")
+	l.With(context.Background()).Debug("none")
+	l.Debug("root")
+
+	entries := out.entries(t)
+	if len(entries) != 1 || entries[0]["message"] != "near" {
+		t.Errorf("entries = %v, want the DEBUG entry near the deadline only", entries)
+	}
+}
+
+func TestOffLevel(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "off"})
+	l.Error("error")
+	l.DPanic("dpanic")
+	if out.String() != "" {
+		t.Errorf("entries logged at the off level: %s", out.String())
+	}
+
+	l, out = newBufferLogger(t, Config{DisableLevelAudit: true})
+	if err := l.SetLevel("silent"); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("error")
+	if out.String() != "" {
+		t.Errorf("entries logged at the silent level: %s", out.String())
+	}
+	if name := levelName(offLevel); name != "off" {
+		t.Errorf("levelName(offLevel) = %q, want off", name)
+	}
+}
+
+func TestWithLevel(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info", DisableLevelAudit: true})
+	pinned := l.WithLevel("debug")
+	if err := l.SetLevel("error"); err != nil {
+		t.Fatal(err)
+	}
+	pinned.Debug("pinned")
+	l.Info("root")
+
+	entries := out.entries(t)
+	if len(entries) != 1 || entries[0]["message"] != "pinned" {
+		t.Errorf("entries = %v, want the entry of the pinned logger only", entries)
+	}
+
+	out.Reset()
+	if l.WithLevel("verbose") != l {
+		t.Error("WithLevel did not return the logger for an invalid level")
+	}
+	if entries := out.entries(t); len(entries) != 1 || entries[0]["level"] != "dpanic" {
+		t.Errorf("entries = %v, want a DPANIC entry reporting the invalid level", entries)
+	}
+}
+
+func TestVerbose(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info"})
+	l.Verbose(func(vl Logger) {
+		vl.Debug("verbose")
+		l.Debug("outside")
+	})
+	l.Debug("after")
+
+	entries := out.entries(t)
+	if len(entries) != 1 || entries[0]["message"] != "verbose" {
+		t.Errorf("entries = %v, want the DEBUG entry of the verbose logger only", entries)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info"})
+	h := l.LevelHandler()
+	serve := func(method, body string) (int, map[string]string) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/level", strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var resp map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s response %q: %v", method, rec.Body.String(), err)
+		}
+		return rec.Code, resp
+	}
+
+	if code, resp := serve(http.MethodGet, ""); code != http.StatusOK || resp["level"] != "info" {
+		t.Errorf("GET = %d %v, want 200 info", code, resp)
+	}
+
+	if code, resp := serve(http.MethodPut, `{"level":"debug"}`); code != http.StatusOK || resp["level"] != "debug" {
+		t.Errorf("PUT = %d %v, want 200 debug", code, resp)
+	}
+	entries := out.entries(t)
+	if len(entries) != 1 || entries[0]["new_level"] != "debug" || entries[0]["remote_addr"] != "10.0.0.1:1234" {
+		t.Errorf("audit entries = %v, want the change with the remote address", entries)
+	}
+	if code, resp := serve(http.MethodGet, ""); code != http.StatusOK || resp["level"] != "debug" {
+		t.Errorf("GET after PUT = %d %v, want 200 debug", code, resp)
+	}
+
+	for _, tt := range []struct {
+		method, body string
+		code         int
+	}{
+		{http.MethodPut, `{"level":`, http.StatusBadRequest},
+		{http.MethodPut, `{"level":"verbose"}`, http.StatusBadRequest},
+		{http.MethodPost, `{"level":"info"}`, http.StatusMethodNotAllowed},
+	} {
+		if code, resp := serve(tt.method, tt.body); code != tt.code || resp["error"] == "" {
+			t.Errorf("%s %s = %d %v, want %d with an error", tt.method, tt.body, code, resp, tt.code)
+		}
+	}
+	if lvl := l.level.Level(); lvl != zapcore.DebugLevel {
+		t.Errorf("level = %v after the rejected requests, want debug", lvl)
+	}
+}
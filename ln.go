@@ -0,0 +1,90 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Debugln uses fmt.Sprintln to construct and log a message at DEBUG level.
+//
+// Unlike Debug, which follows fmt.Sprint and adds spaces only between operands when neither is a string,
+// the ln methods always add spaces between operands. The trailing newline is not part of the message.
+func (l *logger) Debugln(args ...interface{}) {
+	l.logln(zapcore.DebugLevel, args)
+}
+
+// Infoln uses fmt.Sprintln to construct and log a message at INFO level.
+func (l *logger) Infoln(args ...interface{}) {
+	l.logln(zapcore.InfoLevel, args)
+}
+
+// Warnln uses fmt.Sprintln to construct and log a message at WARN level.
+func (l *logger) Warnln(args ...interface{}) {
+	l.logln(zapcore.WarnLevel, args)
+}
+
+// Errorln uses fmt.Sprintln to construct and log a message at ERROR level.
+func (l *logger) Errorln(args ...interface{}) {
+	l.logln(zapcore.ErrorLevel, args)
+}
+
+// Debug uses fmt.Sprint to construct and log a message at DEBUG level, or fmt.Sprintln like Debugln
+// if Config.SpaceOperands is set.
+func (l *logger) Debug(args ...interface{}) {
+	l.log(zapcore.DebugLevel, args)
+}
+
+// Info uses fmt.Sprint to construct and log a message at INFO level, or fmt.Sprintln like Infoln
+// if Config.SpaceOperands is set.
+func (l *logger) Info(args ...interface{}) {
+	l.log(zapcore.InfoLevel, args)
+}
+
+// Warn uses fmt.Sprint to construct and log a message at WARN level, or fmt.Sprintln like Warnln
+// if Config.SpaceOperands is set.
+func (l *logger) Warn(args ...interface{}) {
+	l.log(zapcore.WarnLevel, args)
+}
+
+// Error uses fmt.Sprint to construct and log a message at ERROR level, or fmt.Sprintln like Errorln
+// if Config.SpaceOperands is set.
+func (l *logger) Error(args ...interface{}) {
+	l.log(zapcore.ErrorLevel, args)
+}
+
+// log logs the args joined as set by Config.SpaceOperands. It must be called directly by the method called by the user.
+func (l *logger) log(lvl zapcore.Level, args []interface{}) {
+	zl := l.SugaredLogger.Desugar()
+	if !zl.Core().Enabled(lvl) {
+		return
+	}
+
+	var msg string
+	if l.spaceOperands {
+		msg = sprintln(args)
+	} else {
+		msg = fmt.Sprint(args...)
+	}
+	if ce := zl.WithOptions(zap.AddCallerSkip(2)).Check(lvl, msg); ce != nil {
+		ce.Write()
+	}
+}
+
+func (l *logger) logln(lvl zapcore.Level, args []interface{}) {
+	zl := l.SugaredLogger.Desugar()
+	if !zl.Core().Enabled(lvl) {
+		return
+	}
+
+	if ce := zl.WithOptions(zap.AddCallerSkip(2)).Check(lvl, sprintln(args)); ce != nil {
+		ce.Write()
+	}
+}
+
+// sprintln joins the args like fmt.Sprintln without the trailing newline.
+func sprintln(args []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLn(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "debug"})
+	l.Infoln("a", "b", 1)
+	l.Info("a", "b", 1)
+	l.Errorln("done")
+
+	entries := out.entries(t)
+	for i, want := range []string{"a b 1", "ab1", "done"} {
+		if entries[i]["message"] != want {
+			t.Errorf("message %d = %q, want %q", i, entries[i]["message"], want)
+		}
+		if caller, _ := entries[i]["caller"].(string); !strings.Contains(caller, "ln_test.go") {
+			t.Errorf("caller %d = %q, want the line calling the logger", i, caller)
+		}
+	}
+	if entries[2]["level"] != "error" {
+		t.Errorf("Errorln level = %v, want error", entries[2]["level"])
+	}
+}
+
+func TestSpaceOperands(t *testing.T) {
+	l, out := newBufferLogger(t, Config{SpaceOperands: true})
+	l.Info("user", "alice", "logged in", 3, "times")
+
+	if msg := out.entries(t)[0]["message"]; msg != "user alice logged in 3 times" {
+		t.Errorf("message = %q, want the operands joined by spaces", msg)
+	}
+}
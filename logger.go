@@ -3,6 +3,9 @@ package log
 import (
 	"context"
 	"net/http"
+	"os"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -15,45 +18,145 @@ import (
 type Logger interface {
 	// With returns a logger based off the root logger and decorates it with the given context and arguments.
 	With(ctx context.Context, args ...interface{}) *logger
+	// WithContext returns the logger decorated like by With and a context carrying it for FromContext.
+	WithContext(ctx context.Context, args ...interface{}) (context.Context, *logger)
 
-	// Debug uses fmt.Sprint to construct and log a message at DEBUG level
+	// Debug uses fmt.Sprint, or fmt.Sprintln if Config.SpaceOperands is set, to construct and log a message
+	// at DEBUG level; the same holds for Info and Error
 	Debug(args ...interface{})
 	// Info uses fmt.Sprint to construct and log a message at INFO level
 	Info(args ...interface{})
 	// Error uses fmt.Sprint to construct and log a message at ERROR level
 	Error(args ...interface{})
 
-	// Debugf uses fmt.Sprintf to construct and log a message at DEBUG level
+	// Debugf uses fmt.Sprintf to construct and log a message at DEBUG level.
+	// The level is checked before formatting, so neither fmt.Sprintf nor the Stringers among args
+	// are evaluated while DEBUG is disabled; the same holds for all other levels and Print methods.
 	Debugf(format string, args ...interface{})
 	// Infof uses fmt.Sprintf to construct and log a message at INFO level
 	Infof(format string, args ...interface{})
 	// Errorf uses fmt.Sprintf to construct and log a message at ERROR level
 	Errorf(format string, args ...interface{})
+
+	// Debugln uses fmt.Sprintln to construct and log a message at DEBUG level
+	Debugln(args ...interface{})
+	// Infoln uses fmt.Sprintln to construct and log a message at INFO level
+	Infoln(args ...interface{})
+	// Warnln uses fmt.Sprintln to construct and log a message at WARN level
+	Warnln(args ...interface{})
+	// Errorln uses fmt.Sprintln to construct and log a message at ERROR level
+	Errorln(args ...interface{})
+
+	// DebugCtxf is a shortcut for With(ctx).Debugf(format, args...)
+	DebugCtxf(ctx context.Context, format string, args ...interface{})
+	// InfoCtxf is a shortcut for With(ctx).Infof(format, args...)
+	InfoCtxf(ctx context.Context, format string, args ...interface{})
+	// WarnCtxf is a shortcut for With(ctx).Warnf(format, args...)
+	WarnCtxf(ctx context.Context, format string, args ...interface{})
+	// ErrorCtxf is a shortcut for With(ctx).Errorf(format, args...)
+	ErrorCtxf(ctx context.Context, format string, args ...interface{})
+
+	// Log logs a message at the level, which may be one registered by RegisterLevel, with the key and value pairs
+	Log(level zapcore.Level, msg string, keysAndValues ...interface{})
+	// Check returns a CheckedEntry to write the fields to if logging at the level is enabled, or nil otherwise
+	Check(level zapcore.Level, msg string) *zapcore.CheckedEntry
+
 	// Sync synchronises logging
 	Sync() error
-	// Print uses fmt.Sprint to construct and log a message at DEBUG level
+	// SyncTimeout synchronises logging giving up after d
+	SyncTimeout(d time.Duration) error
+	// SyncContext synchronises logging giving up when ctx is done
+	SyncContext(ctx context.Context) error
+	// Close flushes and closes the outputs of the logger
+	Close() error
+	// SetLevel changes the minimal enabled level of the logger
+	SetLevel(level string) error
+	// SetEncoding switches the logger to the encoding keeping its level and fields
+	SetEncoding(encoding string) error
+	// SetSampling replaces the sampling of the logger, nil disables it
+	SetSampling(conf *SamplingConfig) error
+	// WithLevel returns a logger based off the logger whose minimal enabled level is pinned to level.
+	WithLevel(level string) *logger
+	// Verbose calls fn with a logger based off the logger which logs at DEBUG level
+	Verbose(fn func(Logger))
+	// Named returns a logger based off the logger with the name segment added to its name
+	Named(name string) *logger
+	// SetNamedLevel sets the minimal enabled level of the loggers with the full name
+	SetNamedLevel(name, level string) error
+	// WithHooks returns a logger based off the logger which calls the hooks with every entry it writes
+	WithHooks(hooks ...func(zapcore.Entry) error) *logger
+	// MuteNamed silences the loggers with the full name and their children until UnmuteNamed
+	MuteNamed(name string)
+	// UnmuteNamed reverts MuteNamed for the name
+	UnmuteNamed(name string)
+	// Print uses fmt.Sprint to construct and log a message at Config.PrintLevel, DEBUG by default
 	Print(v ...interface{})
-	// Printf uses fmt.Sprintf to construct and log a message at DEBUG level
+	// Printf uses fmt.Sprintf to construct and log a message at Config.PrintLevel, DEBUG by default
 	Printf(string, ...interface{})
 	//	ZapLogger returns pointer *zap.Logger
 	ZapLogger() *zap.Logger
+	// EntryCount returns the number of the entries written per level, nil unless Config.CountEntries is set
+	EntryCount() map[zapcore.Level]int64
+	// OutputPaths returns the destinations the logger writes to
+	OutputPaths() []string
 }
 
 // Logger struct
 type logger struct {
 	*zap.SugaredLogger
-	zapLogger *zap.Logger
+	// callerSkipped is the SugaredLogger reporting the caller of its caller, see skipCaller
+	callerSkipped     *zap.SugaredLogger
+	zapLogger         *zap.Logger
+	level             zap.AtomicLevel
+	disableLevelAudit bool
+	deadlineDebug     time.Duration
+	baggageKeys       []string
+	outputPaths       []string
+	logQueryArgs      bool
+	maxQueryLength    int
+	ring              *ring
+	name              string
+	namedLevels       *namedLevels
+	// correlationFromRequestID is Config.CorrelationFromRequestID
+	correlationFromRequestID bool
+	// strictFormat is Config.StrictFormat
+	strictFormat bool
+	// spaceOperands is Config.SpaceOperands
+	spaceOperands bool
+	// printLevel is the level of Print and Printf
+	printLevel zapcore.Level
+	// nestContextFields is Config.NestContextFields
+	nestContextFields bool
+	// spanEvents is set if Config.SpanEventLevel is
+	spanEvents bool
+	// requestID, correlationID, route and tenant are the values already added to the logger by With
+	requestID     string
+	correlationID string
+	route         string
+	tenant        string
+	// ctxFields are the fields of the baggage and the context extractors already added to the logger by With
+	ctxFields []zap.Field
+	// encoders and sampling are the switches of a logger built by New
+	encoders *encoderSwitch
+	sampling *samplingSwitch
+	// counts are the entry counts of a logger built by New with Config.CountEntries
+	counts *entryCounts
+	// closer closes the outputs of a logger built by New
+	closer *closer
 }
 
 var _ gorm_logger.Writer = (*logger)(nil)
 var _ Logger = (*logger)(nil)
 
+// Print uses fmt.Sprint to construct and log a message at the level set by Config.PrintLevel, DEBUG by default.
 func (l *logger) Print(v ...interface{}) {
-	l.Debug(v)
+	l.log(l.printLevel, v)
 }
 
+// Printf uses fmt.Sprintf to construct and log a message at the level set by Config.PrintLevel, DEBUG by default.
 func (l *logger) Printf(format string, v ...interface{}) {
-	l.Debugf(format, v)
+	l.checkFormat(format, v)
+	l.logf(l.printLevel, format, v)
 }
 
 type contextKey int
@@ -61,10 +164,18 @@ type contextKey int
 const (
 	requestIDKey contextKey = iota
 	correlationIDKey
+	samplingKeyKey
+	routeKey
+	loggerKey
+	tenantKey
+	// extractedFieldsKey holds the fields of the context extractors copied by DetachContext
+	extractedFieldsKey
 )
 
 var defaultZapConfig = zap.Config{
 	Encoding: "json",
+	// the errors of the outputs are reported to stderr, see Config.OnInternalError
+	ErrorOutputPaths: []string{"stderr"},
 	EncoderConfig: zapcore.EncoderConfig{
 		MessageKey:     "message",
 		LevelKey:       "level",
@@ -73,7 +184,7 @@ var defaultZapConfig = zap.Config{
 		CallerKey:      "caller",
 		StacktraceKey:  "",
 		LineEnding:     "",
-		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeLevel:    levelEncoder,
 		EncodeTime:     zapcore.ISO8601TimeEncoder,
 		EncodeDuration: nil,
 		EncodeCaller:   zapcore.FullCallerEncoder,
@@ -83,25 +194,185 @@ var defaultZapConfig = zap.Config{
 
 // Config for a logger
 type Config struct {
-	Encoding      string
-	OutputPaths   []string
-	Level         string
+	// Encoding is "json", "console" or "proto" for length-prefixed protobuf messages described by logentry.proto
+	Encoding string
+	// OutputPaths are the paths or URLs the entries are written to, "stderr" if empty
+	OutputPaths []string
+	// Level is one of the zapcore level names, a level registered by RegisterLevel or "off" (alias "silent")
+	// to disable all output
+	Level string
+	// InitialFields are added to every entry sorted by key, so the output does not depend on the map order
 	InitialFields map[string]interface{}
+	// BaggageKeys is the allowlist of OpenTelemetry baggage members added by With (requires the "otel" build tag)
+	BaggageKeys []string
+	// Sampling samples the entries unless Development is set
+	Sampling *SamplingConfig
+	// Development marks the development and staging environments, where everything is logged: Sampling is ignored then.
+	// The DPanic entries panic in development.
+	Development bool
+	// DisableLevelAudit suppresses the "logger.level.changed" entry written by SetLevel
+	DisableLevelAudit bool
+	// GzipOutput compresses everything written to the files among OutputPaths
+	GzipOutput bool
+	// IncludeGoroutineID adds the "goroutine" field with the ID of the logging goroutine, it is costly and meant for debug builds
+	IncludeGoroutineID bool
+	// IncludeNumericLevel adds the "level_num" field with the numeric level (debug=-1, info=0, warn=1, error=2, ...)
+	IncludeNumericLevel bool
+	// DeadlineDebugThreshold makes With enable DEBUG for the contexts whose deadline is closer than the threshold (experimental)
+	DeadlineDebugThreshold time.Duration
+	// FlattenNested flattens nested objects and namespaces into dotted keys like "http.status"
+	FlattenNested bool
+	// IncludeCallerPackage adds the "pkg" field with the package of the caller reported in the "caller" field
+	IncludeCallerPackage bool
+	// LogQueryArgs makes Query log the values of the query arguments, for local debugging only
+	LogQueryArgs bool
+	// MaxQueryLength truncates the queries logged by Query to the given number of bytes, unlimited if zero
+	MaxQueryLength int
+	// RingBufferSize is the number of the most recent entries kept in memory for Recent, none if zero
+	RingBufferSize int
+	// MaxMessageBytes truncates longer messages marking them by "…[truncated]", unlimited if zero
+	MaxMessageBytes int
+	// EncoderConfig replaces the default encoder config as a whole if set. It takes precedence over all the other
+	// options affecting the encoder config, which are ignored then.
+	EncoderConfig *zapcore.EncoderConfig
+	// Network configures the "tcp://" and "udp://" outputs among OutputPaths
+	Network NetworkConfig
+	// FailoverErrors is the number of consecutive write errors of the outputs after which the entries are written
+	// to stderr until the outputs recover, no failover if zero
+	FailoverErrors int
+	// KeyCase converts the field keys to "snake" or "camel" case, e.g. "HTTPStatus" to "http_status", kept if "none"
+	KeyCase string
+	// IncludeHostPID adds the "host" and "pid" fields to every entry, unless InitialFields set them
+	IncludeHostPID bool
+	// PreserveNewlines keeps the newlines of multi-line messages in the console encoding, indenting the continuation
+	// lines by a tab so every entry still starts on an unindented line
+	PreserveNewlines bool
+	// Preset adapts the field keys to a log platform: "ecs" follows the Elastic Common Schema, e.g. "@timestamp",
+	// "log.level" and "error.message". The errors are only adapted if logged under the "error" key.
+	Preset string
+	// IncludeUptime adds the "uptime_ms" field with the milliseconds elapsed since the logger was created
+	IncludeUptime bool
+	// SanitizeControlChars escapes the control characters like "\r\n" in the messages of the console encoding,
+	// which writes them verbatim unlike the JSON one, to prevent log injection; on if nil
+	SanitizeControlChars *bool
+	// MaxFieldBytes truncates longer string field values marking them by "…[truncated N bytes]", unlimited if zero
+	MaxFieldBytes int
+	// DebugMirror is the path all the entries are mirrored to regardless of the level, e.g. to keep a full debug
+	// trail locally while the outputs get INFO and above; nothing is mirrored if empty
+	DebugMirror string
+	// IncludeFunction adds the "func" field with the fully qualified name of the calling function
+	IncludeFunction bool
+	// StacktraceIf adds the "stacktrace" field to the entries whose error field, passed with the logging call,
+	// matches the predicate, e.g. to capture the stack traces of the unexpected errors only
+	StacktraceIf func(error) bool
+	// BatchWrites writes the entries to the outputs in JSON array batches if set
+	BatchWrites *BatchConfig
+	// CallerLevels are the names of the levels whose entries get the caller, e.g. "warn" and "error"; all if nil
+	CallerLevels []string
+	// CorrelationFromRequestID makes With add the request ID as the correlation ID of the contexts without one,
+	// so every entry with a request ID has a correlation ID too
+	CorrelationFromRequestID bool
+	// OnInternalError is called with the errors of the writes and syncs of the outputs, e.g. to alert on a full disk,
+	// besides reporting them to stderr. It is called synchronously, so it must not log by the same logger.
+	// The network outputs report no errors unless Synchronous is set, as they send the entries in the background.
+	OnInternalError func(error)
+	// StrictFormat makes the f-methods, like Infof, log a DPanic entry if the number of the arguments does not match
+	// the verbs of the format, e.g. for Infof("50% done"). The DPanic entry panics if Development is set.
+	StrictFormat bool
+	// SpanEventLevel makes the loggers derived via With from a context with a recording OpenTelemetry span
+	// add their entries at this level and above, like "error", as events to the span (requires the "otel" build tag).
+	// No events are added if empty.
+	SpanEventLevel string
+	// CountEntries makes the logger count the entries it writes per level, see EntryCount
+	CountEntries bool
+	// SpaceOperands makes Debug, Info, Warn, Error and Print join their operands like the ln methods, e.g. Info("a", 1, "b")
+	// logs "a 1 b" instead of "a1b". By default they follow fmt.Sprint, which adds spaces only between operands
+	// when neither is a string.
+	SpaceOperands bool
+	// PrintLevel is the level Print and Printf log at, "debug" if empty. E.g. "info" makes the messages of a library
+	// logging through Print visible at the INFO level, like the standard log package prints them unconditionally.
+	PrintLevel string
+	// NestContextFields makes With add the fields taken from the context, like RequestID, CorrelationID and the fields
+	// of RegisterContextExtractor, as a "ctx" object instead of the top-level fields. An object is used rather than
+	// a zap.Namespace, which would nest the fields added to the entries later as well.
+	NestContextFields bool
+	// Synchronous makes every entry reach the outputs before the logging call returns: BatchWrites is ignored
+	// and the network outputs send the entries themselves instead of queueing them. The tests asserting
+	// on the output should set it, so they do not depend on the timing of the background writes.
+	// The loggers of logtest.NewTestLogger and logtest.NewChannelLogger are always synchronous.
+	Synchronous bool
 }
 
 // New creates a new logger
 func New(conf Config) (*logger, error) {
+	if len(conf.OutputPaths) == 0 {
+		conf.OutputPaths = []string{"stderr"}
+	}
+	return newLogger(conf, nil)
+}
+
+// NewWithSyncer creates a new logger like New, which writes to ws instead of conf.OutputPaths, e.g. for tests
+// which need control over the writes and Sync results of the output.
+func NewWithSyncer(ws zapcore.WriteSyncer, conf Config) (*logger, error) {
+	conf.OutputPaths = nil
+	return newLogger(conf, ws)
+}
+
+// newLogger creates a new logger writing to out, or to conf.OutputPaths if out is nil.
+func newLogger(conf Config, out zapcore.WriteSyncer) (*logger, error) {
 	cfg, err := configToZapConfig(conf)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Can not convert conf to zap conf;\nconf: %v", conf)
 	}
 
-	zapLogger, err := cfg.Build()
+	var tees []zapcore.WriteSyncer
+	var recent *ring
+	if conf.RingBufferSize > 0 {
+		recent = newRing(conf.RingBufferSize)
+		tees = append(tees, recent)
+	}
+
+	sampling := conf.Sampling
+	if conf.Development {
+		sampling = nil
+	}
+	samplingSw := newSamplingSwitch(sampling)
+
+	var counts *entryCounts
+	if conf.CountEntries {
+		counts = &entryCounts{}
+	}
+
+	zapLogger, encoders, closeOut, err := build(cfg, conf, out, samplingSw, counts, tees...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Can not build loger by cfg: %#v", cfg)
 	}
 
 	logger := NewWithZap(zapLogger)
+	logger.level = cfg.Level
+	logger.deadlineDebug = conf.DeadlineDebugThreshold
+	logger.disableLevelAudit = conf.DisableLevelAudit
+	logger.baggageKeys = conf.BaggageKeys
+	logger.outputPaths = append([]string(nil), conf.OutputPaths...)
+	if conf.DebugMirror != "" {
+		logger.outputPaths = append(logger.outputPaths, conf.DebugMirror)
+	}
+	logger.logQueryArgs = conf.LogQueryArgs
+	logger.maxQueryLength = conf.MaxQueryLength
+	logger.ring = recent
+	logger.encoders = encoders
+	logger.sampling = samplingSw
+	logger.counts = counts
+	logger.closer = &closer{close: closeOut}
+	logger.correlationFromRequestID = conf.CorrelationFromRequestID
+	logger.strictFormat = conf.StrictFormat
+	logger.spaceOperands = conf.SpaceOperands
+	logger.nestContextFields = conf.NestContextFields
+	if conf.PrintLevel != "" {
+		// the level is validated by configToZapConfig
+		logger.printLevel, _ = parseLevel(conf.PrintLevel)
+	}
+	logger.spanEvents = conf.SpanEventLevel != ""
 
 	logger.Info("Logger construction succeeded")
 	return logger, nil
@@ -110,20 +381,144 @@ func New(conf Config) (*logger, error) {
 func configToZapConfig(conf Config) (zap.Config, error) {
 	cfg := defaultZapConfig
 	cfg.OutputPaths = conf.OutputPaths
+	if conf.GzipOutput {
+		cfg.OutputPaths = gzipPaths(conf.OutputPaths)
+	}
 	cfg.Encoding = conf.Encoding
+	if conf.EncoderConfig != nil {
+		cfg.EncoderConfig = *conf.EncoderConfig
+	}
 	cfg.InitialFields = make(map[string]interface{}, len(conf.InitialFields))
 
+	if conf.IncludeHostPID {
+		if conf.Preset == presetECS {
+			cfg.InitialFields["host.hostname"] = hostname()
+			cfg.InitialFields["process.pid"] = os.Getpid()
+		} else {
+			cfg.InitialFields["host"] = hostname()
+			cfg.InitialFields["pid"] = os.Getpid()
+		}
+	}
+	if err := applyPreset(&cfg, conf); err != nil {
+		return cfg, err
+	}
 	for key, val := range conf.InitialFields {
 		cfg.InitialFields[key] = val
 	}
 
-	if err := cfg.Level.UnmarshalText([]byte(conf.Level)); err != nil {
-		return cfg, errors.Wrapf(err, "Can not unmarshal text %q, expected one of zapcore.Levels", conf.Level)
+	lvl, err := parseLevel(conf.Level)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Level = zap.NewAtomicLevelAt(lvl)
+	if _, err := parseLevels(conf.CallerLevels); err != nil {
+		return cfg, err
+	}
+	if conf.SpanEventLevel != "" {
+		if _, err := parseLevel(conf.SpanEventLevel); err != nil {
+			return cfg, err
+		}
+	}
+	if conf.PrintLevel != "" {
+		if _, err := parseLevel(conf.PrintLevel); err != nil {
+			return cfg, err
+		}
 	}
 
 	return cfg, nil
 }
 
+// build builds a zap logger from cfg like cfg.Build does, but with the encoder and the cores of the features enabled in conf.
+// The output is out, or cfg.OutputPaths if out is nil. The tees get all the entries written to the outputs,
+// encoded the same way.
+func build(cfg zap.Config, conf Config, out zapcore.WriteSyncer, sampling *samplingSwitch, counts *entryCounts, tees ...zapcore.WriteSyncer) (*zap.Logger, *encoderSwitch, func(), error) {
+	sw, err := newEncoderSwitch(cfg, conf)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sink, closeOut := out, func() {}
+	if sink == nil {
+		if sink, closeOut, err = openSinks(cfg.OutputPaths, conf.Network, conf.Synchronous); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	errSink, _, err := zap.Open(cfg.ErrorOutputPaths...)
+	if err != nil {
+		closeOut()
+		return nil, nil, nil, err
+	}
+	if conf.OnInternalError != nil {
+		if fanout, ok := sink.(*fanoutSink); ok {
+			// the fanout reports the errors of every output, even if the others succeed
+			fanout.onError = conf.OnInternalError
+		} else {
+			sink = &errorHookSink{WriteSyncer: sink, hook: conf.OnInternalError}
+		}
+	}
+	if conf.FailoverErrors > 0 {
+		sink = newFailoverSink(sink, conf.FailoverErrors)
+	}
+	if conf.BatchWrites != nil && !conf.Synchronous {
+		batch := newBatchSink(sink, *conf.BatchWrites)
+		// the pending batch is written before the outputs are closed
+		closeOutputs := closeOut
+		closeOut = func() {
+			batch.Close()
+			closeOutputs()
+		}
+		sink = batch
+	}
+
+	// the core enables all levels, the entries are filtered by the outermost levelCore instead
+	var core zapcore.Core = newSwitchCore(sw, sink)
+	for _, tee := range tees {
+		core = zapcore.NewTee(core, newSwitchCore(sw, tee))
+	}
+	lc := wrapCore(core, conf, cfg.Level, sampling, counts)
+	if conf.DebugMirror != "" {
+		mirror, closeMirror, err := zap.Open(conf.DebugMirror)
+		if err != nil {
+			closeOut()
+			return nil, nil, nil, err
+		}
+		// the mirror gets the entries rewritten like the outputs, but neither sampled nor counted
+		lc.mirror = &levelCore{Core: rewriteEntries(newSwitchCore(sw, mirror), conf), enab: zapcore.DebugLevel}
+		closeOutputs := closeOut
+		closeOut = func() {
+			closeOutputs()
+			closeMirror()
+		}
+	}
+
+	opts := []zap.Option{
+		zap.ErrorOutput(errSink),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.Fields(initialFields(cfg.InitialFields)...),
+	}
+	if conf.Development {
+		// DPanic entries panic
+		opts = append(opts, zap.Development())
+	}
+	return zap.New(lc, opts...), sw, closeOut, nil
+}
+
+// initialFields returns the fields sorted by key.
+func initialFields(m map[string]interface{}) []zap.Field {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]zap.Field, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, zap.Any(key, m[key]))
+	}
+	return fields
+}
+
 // NewByDefault creates a new logger using the default configuration.
 func NewByDefault() *logger {
 	l, _ := zap.NewProduction()
@@ -132,46 +527,155 @@ func NewByDefault() *logger {
 
 // NewWithZap creates a new logger using the preconfigured zap logger.
 func NewWithZap(l *zap.Logger) *logger {
-	return &logger{
-		SugaredLogger: l.Sugar(),
-		zapLogger:     l,
+	logger := &logger{
+		zapLogger:   l,
+		namedLevels: newNamedLevels(),
+		printLevel:  zapcore.DebugLevel,
 	}
+	logger.setSugar(l.Sugar())
+	return logger
+}
+
+// NewWithCore creates a logger writing to the core, which must enable all the levels the logger may log at.
+// The logger logs at DEBUG level until SetLevel changes the level.
+func NewWithCore(core zapcore.Core, opts ...zap.Option) *logger {
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	zl := zap.New(&levelCore{Core: core, enab: level}, append([]zap.Option{zap.AddCaller()}, opts...)...)
+
+	logger := NewWithZap(zl)
+	logger.level = level
+	return logger
 }
 
 func (l *logger) ZapLogger() *zap.Logger {
 	return l.zapLogger
 }
 
+// OutputPaths returns the destinations the logger writes to as they were given in Config.OutputPaths,
+// followed by Config.DebugMirror if set, or nil if the logger was not created by New.
+func (l *logger) OutputPaths() []string {
+	return append([]string(nil), l.outputPaths...)
+}
+
 // With returns a logger based off the root logger and decorates it with the given context and arguments.
 //
 // If the context contains request ID and/or correlation ID information (recorded via WithRequestID()
 // and WithCorrelationID()), they will be added to every log message generated by the new logger.
+// OpenTelemetry baggage members listed in Config.BaggageKeys are added the same way. The fields taken from
+// the context are nested in a "ctx" object if Config.NestContextFields is set.
+// If the context deadline is closer than Config.DeadlineDebugThreshold, the new logger logs at DEBUG level
+// regardless of the logger level to capture what happens right before the timeout.
 //
 // The arguments should be specified as a sequence of name, value pairs with names being strings.
 // The arguments will also be added to every log message generated by the logger.
 func (l *logger) With(ctx context.Context, args ...interface{}) *logger {
+	requestID, correlationID, route, tenant := l.requestID, l.correlationID, l.route, l.tenant
+	ctxFields := l.ctxFields
+	// fields are the fields taken from the context
+	var fields []zap.Field
 	if ctx != nil {
-		if id, ok := ctx.Value(requestIDKey).(string); ok {
-			args = append(args, zap.String("RequestID", id))
+		// the IDs and fields the logger already carries are not added again, so With may be called again
+		// with the same context, e.g. on a logger returned by FromContext
+		if id, ok := ctx.Value(requestIDKey).(string); ok && id != requestID {
+			fields = append(fields, zap.String("RequestID", id))
+			requestID = id
 		}
-		if id, ok := ctx.Value(correlationIDKey).(string); ok {
-			args = append(args, zap.String("CorrelationID", id))
+		id, ok := ctx.Value(correlationIDKey).(string)
+		if !ok && l.correlationFromRequestID {
+			id, ok = ctx.Value(requestIDKey).(string)
+		}
+		if ok && id != correlationID {
+			fields = append(fields, zap.String("CorrelationID", id))
+			correlationID = id
+		}
+		if r, ok := ctx.Value(routeKey).(string); ok && r != route {
+			fields = append(fields, zap.String("route", r))
+			route = r
+		}
+		if t, ok := ctx.Value(tenantKey).(string); ok && t != tenant {
+			fields = append(fields, zap.String("tenant_id", t))
+			tenant = t
+		}
+		if key, ok := ctx.Value(samplingKeyKey).(string); ok {
+			args = append(args, samplingKey(key))
+		}
+		if l.spanEvents {
+			if span := spanFromContext(ctx); span != nil {
+				args = append(args, spanFieldOf(span))
+			}
+		}
+		for _, f := range append(baggageFields(ctx, l.baggageKeys), extractedFields(ctx)...) {
+			if field := f.(zap.Field); !containsField(ctxFields, field) {
+				fields = append(fields, field)
+				ctxFields = append(ctxFields[:len(ctxFields):len(ctxFields)], field)
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && l.deadlineDebug > 0 && time.Until(deadline) < l.deadlineDebug {
+			l = l.withLevelEnabler(zapcore.DebugLevel)
 		}
 	}
-	if len(args) > 0 {
-		return &logger{
-			SugaredLogger: l.SugaredLogger.With(args...),
-			zapLogger:     l.zapLogger,
+
+	if l.nestContextFields && len(fields) > 0 {
+		args = append(args, nestedFields("ctx", fields))
+	} else {
+		for _, f := range fields {
+			args = append(args, f)
+		}
+	}
+
+	nl := l.with(args...)
+	if nl != l {
+		nl.requestID, nl.correlationID, nl.route, nl.tenant = requestID, correlationID, route, tenant
+		nl.ctxFields = ctxFields
+	}
+	return nl
+}
+
+// nestedFields constructs an object field with the fields.
+func nestedFields(key string, fields []zap.Field) zap.Field {
+	return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		for _, f := range fields {
+			f.AddTo(enc)
+		}
+		return nil
+	}))
+}
+
+// containsField tells whether fields contain a field equal to f.
+func containsField(fields []zap.Field, f zap.Field) bool {
+	if f.Type == zapcore.StringerType {
+		// the Stringers may not be comparable
+		return false
+	}
+	for _, field := range fields {
+		if field.Equals(f) {
+			return true
 		}
 	}
+	return false
+}
+
+// with returns a logger based off l and decorated with the given arguments.
+func (l *logger) with(args ...interface{}) *logger {
+	if len(args) > 0 {
+		nl := *l
+		nl.setSugar(l.SugaredLogger.With(args...))
+		return &nl
+	}
 	return l
 }
 
+// NewID generates the request IDs for the requests without one. It may be replaced, e.g. by a generator
+// of time-ordered IDs or by a deterministic one in tests.
+var NewID = func() string {
+	return uuid.New().String()
+}
+
 // WithRequest returns a context which knows the request ID and correlation ID in the given request.
 func WithRequest(ctx context.Context, req *http.Request) context.Context {
 	id := getRequestID(req)
 	if id == "" {
-		id = uuid.New().String()
+		id = NewID()
 	}
 	ctx = context.WithValue(ctx, requestIDKey, id)
 	if id := getCorrelationID(req); id != "" {
@@ -180,6 +684,40 @@ func WithRequest(ctx context.Context, req *http.Request) context.Context {
 	return ctx
 }
 
+// WithGeneratedRequestID returns a context with a request ID generated by gen, e.g. prefixed by the region,
+// unless ctx already knows a request ID, in which case ctx is returned. Unlike replacing NewID, it allows
+// different generators for different contexts.
+func WithGeneratedRequestID(ctx context.Context, gen func() string) context.Context {
+	if RequestID(ctx) != "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey, gen())
+}
+
+// RequestID returns the request ID recorded in the context by WithRequest or WithGeneratedRequestID, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// CorrelationID returns the correlation ID recorded in the context by WithRequest, or "" if there is none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// WithRoute returns a context which makes loggers derived via With add the route pattern matched for the request,
+// like "/users/{id}", as the "route" field.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// Route returns the route pattern recorded in the context by WithRoute, or "" if there is none.
+func Route(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey).(string)
+	return route
+}
+
 // getCorrelationID extracts the correlation ID from the HTTP request
 func getCorrelationID(req *http.Request) string {
 	return req.Header.Get("X-Correlation-ID")
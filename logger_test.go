@@ -0,0 +1,245 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// bufferSyncer is a zapcore.WriteSyncer collecting the output in memory.
+type bufferSyncer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *bufferSyncer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *bufferSyncer) Sync() error { return nil }
+
+func (b *bufferSyncer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *bufferSyncer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+// entries decodes the JSON entries written to b.
+func (b *bufferSyncer) entries(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace([]byte(b.String())), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("invalid entry %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// newBufferLogger creates a logger writing to the returned buffer by NewWithSyncer, with the "json" encoding
+// unless conf sets another one. The "Logger construction succeeded" entry is discarded.
+func newBufferLogger(t testing.TB, conf Config) (*logger, *bufferSyncer) {
+	t.Helper()
+	if conf.Encoding == "" {
+		conf.Encoding = "json"
+	}
+	out := &bufferSyncer{}
+	l, err := NewWithSyncer(out, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	return l, out
+}
+
+func TestInitialFieldsOrder(t *testing.T) {
+	fields := map[string]interface{}{"service": "api", "env": "prod", "zone": "a", "app": "x", "version": 2}
+	var first string
+	for i := 0; i < 20; i++ {
+		l, out := newBufferLogger(t, Config{InitialFields: fields})
+		l.Info("m")
+		line := out.String()
+		line = line[strings.Index(line, `"message"`):]
+		if first == "" {
+			first = line
+			if !strings.Contains(line, `"app":"x","env":"prod","service":"api","version":2,"zone":"a"`) {
+				t.Fatalf("initial fields not sorted by key: %s", line)
+			}
+		} else if line != first {
+			t.Fatalf("initial fields order changed: %s, was %s", line, first)
+		}
+	}
+}
+
+func TestNewID(t *testing.T) {
+	defer func(newID func() string) { NewID = newID }(NewID)
+	NewID = func() string { return "generated" }
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if id := RequestID(WithRequest(context.Background(), req)); id != "generated" {
+		t.Errorf("RequestID = %q, want the generated ID", id)
+	}
+	req.Header.Set("X-Request-ID", "given")
+	if id := RequestID(WithRequest(context.Background(), req)); id != "given" {
+		t.Errorf("RequestID = %q, want the ID of the request", id)
+	}
+}
+
+func TestOutputPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+
+	l, err := New(Config{Encoding: "json", OutputPaths: []string{a, b}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	paths := l.OutputPaths()
+	if len(paths) != 2 || paths[0] != a || paths[1] != b {
+		t.Errorf("OutputPaths() = %v, want [%s %s]", paths, a, b)
+	}
+	paths[0] = "changed"
+	if l.OutputPaths()[0] != a {
+		t.Error("OutputPaths() returned the internal slice")
+	}
+	if paths := NewByDefault().OutputPaths(); paths != nil {
+		t.Errorf("OutputPaths() = %v for a logger not created by New, want nil", paths)
+	}
+}
+
+func TestEncoderConfig(t *testing.T) {
+	l, out := newBufferLogger(t, Config{EncoderConfig: &zapcore.EncoderConfig{
+		MessageKey:  "msg",
+		LevelKey:    "severity",
+		EncodeLevel: zapcore.CapitalLevelEncoder,
+	}})
+	l.Info("m")
+
+	entry := out.entries(t)[0]
+	if entry["msg"] != "m" || entry["severity"] != "INFO" {
+		t.Errorf("entry = %v, want the keys of the given encoder config", entry)
+	}
+	if _, ok := entry["time"]; ok {
+		t.Error("time is encoded though the given encoder config has no TimeKey")
+	}
+}
+
+func TestIncludeHostPID(t *testing.T) {
+	l, out := newBufferLogger(t, Config{IncludeHostPID: true})
+	l.Info("m")
+
+	entry := out.entries(t)[0]
+	if entry["host"] != hostname() || entry["pid"] != float64(os.Getpid()) {
+		t.Errorf("host = %v, pid = %v, want %q and %d", entry["host"], entry["pid"], hostname(), os.Getpid())
+	}
+}
+
+func TestWithGeneratedRequestID(t *testing.T) {
+	calls := 0
+	gen := func() string {
+		calls++
+		return "eu-1"
+	}
+	ctx := WithGeneratedRequestID(context.Background(), gen)
+	if id := RequestID(ctx); id != "eu-1" {
+		t.Errorf("RequestID = %q, want the generated ID", id)
+	}
+	if again := WithGeneratedRequestID(ctx, gen); again != ctx || calls != 1 {
+		t.Errorf("the ID was generated again for a context with a request ID")
+	}
+}
+
+func TestEmptyOutputPaths(t *testing.T) {
+	// the level keeps the construction entry off stderr
+	l, err := New(Config{Encoding: "json", Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths := l.OutputPaths(); len(paths) != 1 || paths[0] != "stderr" {
+		t.Errorf("OutputPaths() = %v, want [stderr]", paths)
+	}
+}
+
+func TestNestedWithNoDuplicates(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	ctx := WithRequest(context.Background(), req)
+
+	l.With(ctx).With(ctx, "step", 1).With(ctx).Info("m")
+	for _, key := range []string{`"RequestID"`, `"CorrelationID"`} {
+		if n := strings.Count(out.String(), key); n != 1 {
+			t.Errorf("%s added %d times: %s", key, n, out.String())
+		}
+	}
+
+	// a different request ID is added
+	out.Reset()
+	other := httptest.NewRequest("GET", "/", nil)
+	other.Header.Set("X-Request-ID", "req-2")
+	l.With(ctx).With(WithRequest(context.Background(), other)).Info("m")
+	if !strings.Contains(out.String(), `"RequestID":"req-2"`) {
+		t.Errorf("output = %s, want the second request ID", out.String())
+	}
+}
+
+func TestCorrelationFromRequestID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	ctx := WithRequest(context.Background(), req)
+
+	for _, fallback := range []bool{false, true} {
+		l, out := newBufferLogger(t, Config{CorrelationFromRequestID: fallback})
+		l.With(ctx).Info("m")
+		id, ok := out.entries(t)[0]["CorrelationID"]
+		if fallback && id != "req-1" {
+			t.Errorf("CorrelationID = %v, want the request ID", id)
+		}
+		if !fallback && ok {
+			t.Errorf("CorrelationID = %v without the fallback", id)
+		}
+	}
+}
+
+func TestNestContextFields(t *testing.T) {
+	l, out := newBufferLogger(t, Config{NestContextFields: true})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	l.With(WithRequest(context.Background(), req), "user", "alice").Info("m")
+
+	entry := out.entries(t)[0]
+	ctx, _ := entry["ctx"].(map[string]interface{})
+	if ctx["RequestID"] != "req-1" || entry["user"] != "alice" {
+		t.Errorf("entry = %v, want the request ID in ctx and the user at the top level", entry)
+	}
+	if _, ok := entry["RequestID"]; ok {
+		t.Error("RequestID is added at the top level")
+	}
+}
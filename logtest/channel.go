@@ -0,0 +1,46 @@
+package logtest
+
+import (
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/minipkg/log"
+)
+
+// NewChannelLogger creates a new logger which delivers the entries of all levels to the returned channel
+// with the given buffer size, so the tests of concurrent code can synchronize on the logging.
+//
+// A logging call blocks while the channel is full, so no entry is lost; the test must keep receiving.
+func NewChannelLogger(buf int) (log.Logger, <-chan observer.LoggedEntry) {
+	ch := make(chan observer.LoggedEntry, buf)
+	return log.NewWithCore(&channelCore{ch: ch}), ch
+}
+
+// channelCore is a zapcore.Core which sends the entries with their fields to a channel.
+type channelCore struct {
+	ch     chan<- observer.LoggedEntry
+	fields []zapcore.Field
+}
+
+func (c *channelCore) Enabled(lvl zapcore.Level) bool {
+	// the entries are filtered by the level of the logger
+	return true
+}
+
+func (c *channelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &channelCore{ch: c.ch, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c *channelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *channelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	c.ch <- observer.LoggedEntry{Entry: ent, Context: append(append(all, c.fields...), fields...)}
+	return nil
+}
+
+func (c *channelCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package logtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewChannelLogger(t *testing.T) {
+	l, entries := NewChannelLogger(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l.With(context.Background(), "worker", i).Debug("done")
+		}(i)
+	}
+
+	seen := map[int64]bool{}
+	for i := 0; i < 3; i++ {
+		e := <-entries
+		if e.Level != zapcore.DebugLevel || e.Message != "done" {
+			t.Errorf("entry = %v %q, want DEBUG done", e.Level, e.Message)
+		}
+		seen[e.ContextMap()["worker"].(int64)] = true
+	}
+	wg.Wait()
+	if len(seen) != 3 {
+		t.Errorf("workers seen = %v, want all 3", seen)
+	}
+
+	if err := l.SetLevel("info"); err != nil {
+		t.Fatal(err)
+	}
+	<-entries // the level change audit
+	l.Debug("disabled")
+	select {
+	case e := <-entries:
+		t.Errorf("got %q at a disabled level", e.Message)
+	default:
+	}
+}
@@ -0,0 +1,29 @@
+// Package logtest provides the loggers for the tests of the code logging by github.com/minipkg/log.
+package logtest
+
+import (
+	"math"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/minipkg/log"
+)
+
+// NewTestLogger creates a new logger which writes the entries of all levels to t.Log.
+func NewTestLogger(t testing.TB) log.Logger {
+	core := zaptest.NewLogger(t, zaptest.Level(zapcore.Level(math.MinInt8))).Core()
+	return log.NewWithCore(core)
+}
+
+// FailOnError returns a logger like l which fails the test by t.Errorf whenever an entry
+// at ERROR or a higher level is logged, so unexpected error logging does not go unnoticed.
+func FailOnError(t testing.TB, l log.Logger) log.Logger {
+	return l.WithHooks(func(ent zapcore.Entry) error {
+		if ent.Level >= zapcore.ErrorLevel {
+			t.Errorf("unexpected %s entry logged: %s", ent.Level, ent.Message)
+		}
+		return nil
+	})
+}
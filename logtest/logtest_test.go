@@ -0,0 +1,48 @@
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingTB is a testing.TB recording the logs and the errors instead of reporting them.
+type recordingTB struct {
+	testing.TB
+	logs   []string
+	errors []string
+}
+
+func (t *recordingTB) Helper() {}
+
+func (t *recordingTB) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNewTestLogger(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	l := NewTestLogger(tb)
+	l.Debug("debug entry")
+
+	if len(tb.logs) != 1 || !strings.Contains(tb.logs[0], "debug entry") {
+		t.Errorf("logs = %q, want the debug entry", tb.logs)
+	}
+}
+
+func TestFailOnError(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	l := FailOnError(tb, NewTestLogger(tb))
+	l.Info("fine")
+	if len(tb.errors) != 0 {
+		t.Fatalf("errors = %q after an INFO entry", tb.errors)
+	}
+
+	l.Error("broken")
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "broken") {
+		t.Errorf("errors = %q, want the ERROR entry reported", tb.errors)
+	}
+}
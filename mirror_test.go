@@ -0,0 +1,67 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDebugMirror(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "debug.log")
+
+	l, out := newBufferLogger(t, Config{Level: "info", DebugMirror: path, IncludeCallerPackage: true})
+	if got := l.OutputPaths(); len(got) != 1 || got[0] != path {
+		t.Errorf("OutputPaths() = %v, want [%s]", got, path)
+	}
+	l.Debug("debug")
+	l.Info("info")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := out.entries(t)
+	if len(entries) != 1 || entries[0]["message"] != "info" {
+		t.Errorf("the output got %v, want the INFO entry only", entries)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var messages []interface{}
+	for s := bufio.NewScanner(f); s.Scan(); {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(s.Bytes(), &entry); err != nil {
+			t.Fatalf("%v: %s", err, s.Bytes())
+		}
+		if entry["pkg"] != "github.com/minipkg/log" {
+			t.Errorf("mirrored entry %v lacks the pkg field", entry)
+		}
+		messages = append(messages, entry["message"])
+	}
+	if n := len(messages); n < 2 || messages[n-2] != "debug" || messages[n-1] != "info" {
+		t.Errorf("the mirror got %v, want the DEBUG and the INFO entries", messages)
+	}
+}
+
+func TestLevelCoreEnabledWithMirror(t *testing.T) {
+	mirror := &levelCore{Core: zapcore.NewNopCore(), enab: zapcore.InfoLevel}
+	c := &levelCore{Core: zapcore.NewNopCore(), enab: zapcore.ErrorLevel, mirror: mirror}
+	if c.Enabled(zapcore.DebugLevel) {
+		t.Error("DEBUG is enabled while disabled both for the output and the mirror")
+	}
+	if !c.Enabled(zapcore.InfoLevel) {
+		t.Error("INFO is disabled while enabled for the mirror")
+	}
+}
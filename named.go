@@ -0,0 +1,134 @@
+package log
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// namedLevels is the registry of the levels set per logger name, shared by all loggers derived from the same root.
+type namedLevels struct {
+	mu     sync.RWMutex
+	levels map[string]zapcore.Level
+	// muted are the names muted by MuteNamed, kept apart from levels so unmuting restores the level set before
+	muted map[string]bool
+}
+
+func newNamedLevels() *namedLevels {
+	return &namedLevels{levels: make(map[string]zapcore.Level), muted: make(map[string]bool)}
+}
+
+func (r *namedLevels) get(name string) (zapcore.Level, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lvl, ok := r.levels[name]
+	return lvl, ok
+}
+
+func (r *namedLevels) set(name string, lvl zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.levels[name] = lvl
+}
+
+func (r *namedLevels) setMuted(name string, muted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if muted {
+		r.muted[name] = true
+	} else {
+		delete(r.muted, name)
+	}
+}
+
+// isMuted tells whether the name or any of its parent names is muted.
+func (r *namedLevels) isMuted(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.muted) == 0 {
+		return false
+	}
+	for {
+		if r.muted[name] {
+			return true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return false
+		}
+		name = name[:i]
+	}
+}
+
+// namedEnabler enables the levels by the level set for the logger name, or by its fallback if there is none.
+type namedEnabler struct {
+	levels   *namedLevels
+	name     string
+	fallback zapcore.LevelEnabler
+}
+
+func (e *namedEnabler) Enabled(lvl zapcore.Level) bool {
+	if e.levels.isMuted(e.name) {
+		return false
+	}
+	if min, ok := e.levels.get(e.name); ok {
+		return min.Enabled(lvl)
+	}
+	return e.fallback.Enabled(lvl)
+}
+
+// Named returns a logger based off l with the name segment added to its name; segments are joined by periods.
+//
+// The minimal enabled level of the named logger is controlled by SetNamedLevel for its full name,
+// e.g. "db" or "db.pool". Until a level is set for the name, the level of l applies.
+func (l *logger) Named(name string) *logger {
+	if name == "" {
+		return l
+	}
+	full := name
+	if l.name != "" {
+		full = l.name + "." + name
+	}
+
+	zl := l.SugaredLogger.Named(name).Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		var fallback zapcore.LevelEnabler = core
+		var mirror *levelCore
+		if lc, ok := core.(*levelCore); ok {
+			core, fallback, mirror = lc.Core, lc.enab, lc.mirror
+		}
+		return &levelCore{Core: core, enab: &namedEnabler{levels: l.namedLevels, name: full, fallback: fallback}, mirror: mirror}
+	}))
+
+	nl := *l
+	nl.setSugar(zl.Sugar())
+	nl.name = full
+	return &nl
+}
+
+// SetNamedLevel sets the minimal enabled level of the loggers with the full name, created by Named
+// from l or from any logger sharing its root, e.g. to debug the "db" loggers while the others stay at INFO.
+func (l *logger) SetNamedLevel(name, level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.namedLevels.set(name, lvl)
+	return nil
+}
+
+// MuteNamed silences the loggers with the full name and their children, e.g. "db" mutes also "db.pool",
+// until UnmuteNamed is called for the same name.
+func (l *logger) MuteNamed(name string) {
+	l.namedLevels.setMuted(name, true)
+}
+
+// UnmuteNamed reverts MuteNamed, the loggers get the levels they had before muting.
+func (l *logger) UnmuteNamed(name string) {
+	l.namedLevels.setMuted(name, false)
+}
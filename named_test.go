@@ -0,0 +1,47 @@
+package log
+
+import "testing"
+
+func TestSetNamedLevel(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "info"})
+	db, http := l.Named("db"), l.Named("http")
+	if err := l.SetNamedLevel("db", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	db.Debug("db")
+	http.Debug("http")
+	// the child falls back to the level of its parent
+	db.Named("pool").Debug("pool")
+
+	entries := out.entries(t)
+	if len(entries) != 2 || entries[0]["logger"] != "db" || entries[1]["logger"] != "db.pool" {
+		t.Errorf("entries = %v, want the DEBUG entries of the db loggers only", entries)
+	}
+	if err := l.SetNamedLevel("db", "verbose"); err == nil {
+		t.Error("SetNamedLevel accepted an invalid level")
+	}
+}
+
+func TestMuteNamed(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	db, pool, http := l.Named("db"), l.Named("db").Named("pool"), l.Named("http")
+	if err := l.SetNamedLevel("db", "warn"); err != nil {
+		t.Fatal(err)
+	}
+
+	l.MuteNamed("db")
+	db.Error("db")
+	pool.Error("pool")
+	http.Error("http")
+	if entries := out.entries(t); len(entries) != 1 || entries[0]["message"] != "http" {
+		t.Errorf("entries = %v, want the entry of the unmuted logger only", entries)
+	}
+
+	out.Reset()
+	l.UnmuteNamed("db")
+	db.Info("info")
+	db.Warn("warn")
+	if entries := out.entries(t); len(entries) != 1 || entries[0]["message"] != "warn" {
+		t.Errorf("entries = %v, want the level set before muting", entries)
+	}
+}
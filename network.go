@@ -0,0 +1,297 @@
+package log
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultNetworkQueueSize = 1024
+	networkDialTimeout      = 5 * time.Second
+	// networkDropReportInterval is how often the number of the dropped entries is reported to stderr
+	networkDropReportInterval = 10 * time.Second
+	// networkDrainTimeout is how long Sync and Close wait for the queued entries to be sent
+	networkDrainTimeout = 5 * time.Second
+)
+
+// OverflowPolicy tells what a network output does with a new entry when its queue is full.
+type OverflowPolicy int
+
+const (
+	// DropNewest drops the new entry.
+	DropNewest OverflowPolicy = iota
+	// DropOldest drops the oldest queued entry to make room for the new one.
+	DropOldest
+	// Block blocks the logging call until there is room in the queue.
+	Block
+)
+
+// NetworkConfig configures the network outputs given by "tcp://host:port" or "udp://host:port" URLs in OutputPaths.
+//
+// The entries are sent by a background goroutine from a bounded queue, so a slow remote does not block the logging
+// calls unless the Block policy is used. The number of the dropped entries is reported to stderr periodically.
+// With Config.Synchronous the entries are sent by the logging calls, so the queue is not used.
+type NetworkConfig struct {
+	// QueueSize is the number of the queued entries, 1024 if zero
+	QueueSize int
+	Overflow  OverflowPolicy
+}
+
+// openSinks opens the outputs given by paths like zap.Open does, supporting also the network outputs.
+// Several outputs are written by a fanoutSink, so they fail independently of each other. The network outputs
+// send the entries in the logging calls if synchronous is set.
+func openSinks(paths []string, conf NetworkConfig, synchronous bool) (zapcore.WriteSyncer, func(), error) {
+	sinks := make([]zapcore.WriteSyncer, 0, len(paths))
+	var closers []func()
+	closeOut := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	for _, path := range paths {
+		if u, err := url.Parse(path); err == nil && (u.Scheme == "tcp" || u.Scheme == "udp") {
+			sink := newNetworkSink(u.Scheme, u.Host, conf, synchronous)
+			sinks = append(sinks, sink)
+			closers = append(closers, func() { _ = sink.Close() })
+			continue
+		}
+		sink, c, err := zap.Open(path)
+		if err != nil {
+			closeOut()
+			return nil, nil, err
+		}
+		sinks = append(sinks, sink)
+		closers = append(closers, c)
+	}
+	if len(sinks) == 1 {
+		return sinks[0], closeOut, nil
+	}
+	return newFanoutSink(sinks, paths), closeOut, nil
+}
+
+// networkSink is a zapcore.WriteSyncer which sends the entries to a remote address from a bounded queue.
+type networkSink struct {
+	network string
+	addr    string
+	policy  OverflowPolicy
+	queue   chan []byte
+	dropped uint64
+
+	// drained is closed when pending, the number of the entries neither sent nor dropped, drops to zero
+	mu      sync.Mutex
+	drained chan struct{}
+	pending int
+
+	// synchronous makes Write send the entry itself, serialized by sendMu, instead of queueing it
+	synchronous bool
+	sendMu      sync.Mutex
+
+	// closed is set by Close, after which the entries are dropped; stop ends run, which closes stopped
+	closed  int32
+	stop    chan struct{}
+	stopped chan struct{}
+
+	conn net.Conn
+}
+
+func newNetworkSink(network, addr string, conf NetworkConfig, synchronous bool) *networkSink {
+	size := conf.QueueSize
+	if size <= 0 {
+		size = defaultNetworkQueueSize
+	}
+
+	s := &networkSink{
+		network:     network,
+		addr:        addr,
+		policy:      conf.Overflow,
+		synchronous: synchronous,
+	}
+	s.drained = make(chan struct{})
+	close(s.drained)
+	if !synchronous {
+		s.queue = make(chan []byte, size)
+		s.stop = make(chan struct{})
+		s.stopped = make(chan struct{})
+		go s.run()
+	}
+	return s
+}
+
+func (s *networkSink) Write(p []byte) (int, error) {
+	if s.synchronous {
+		s.sendMu.Lock()
+		defer s.sendMu.Unlock()
+
+		if err := s.send(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if atomic.LoadInt32(&s.closed) != 0 {
+		return 0, errors.Errorf("Can not write to closed %s://%s", s.network, s.addr)
+	}
+	entry := append([]byte(nil), p...)
+	s.addPending(1)
+
+	switch s.policy {
+	case Block:
+		select {
+		case s.queue <- entry:
+		case <-s.stop:
+			// Close stopped the sending goroutine after the closed check
+			s.drop()
+			return 0, errors.Errorf("Can not write to closed %s://%s", s.network, s.addr)
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.queue <- entry:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-s.queue:
+				s.drop()
+			default:
+			}
+		}
+	default:
+		select {
+		case s.queue <- entry:
+		default:
+			s.drop()
+		}
+	}
+	return len(p), nil
+}
+
+// Sync waits until all the queued entries are sent or dropped, at most networkDrainTimeout.
+func (s *networkSink) Sync() error {
+	if s.synchronous {
+		return nil
+	}
+	return s.drain(networkDrainTimeout)
+}
+
+// Close waits until the queued entries are sent like Sync does, then stops the sending goroutine, dropping
+// the entries not sent in time, and closes the connection. The later entries are dropped.
+func (s *networkSink) Close() error {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		return nil
+	}
+	if s.synchronous {
+		s.sendMu.Lock()
+		defer s.sendMu.Unlock()
+		s.closeConn()
+		return nil
+	}
+
+	err := s.drain(networkDrainTimeout)
+	close(s.stop)
+	<-s.stopped
+	return err
+}
+
+// drain waits until there are no pending entries, at most timeout.
+func (s *networkSink) drain(timeout time.Duration) error {
+	s.mu.Lock()
+	drained := s.drained
+	s.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+		return errors.Errorf("Can not send the queued entries to %s://%s within %s", s.network, s.addr, timeout)
+	}
+}
+
+func (s *networkSink) run() {
+	ticker := time.NewTicker(networkDropReportInterval)
+	defer ticker.Stop()
+	defer close(s.stopped)
+
+	for {
+		select {
+		case entry := <-s.queue:
+			if err := s.send(entry); err != nil {
+				s.drop()
+				continue
+			}
+			s.addPending(-1)
+		case <-ticker.C:
+			s.reportDropped()
+		case <-s.stop:
+			// the entries left in the queue were not sent in time
+			for len(s.queue) > 0 {
+				<-s.queue
+				s.drop()
+			}
+			s.reportDropped()
+			s.closeConn()
+			return
+		}
+	}
+}
+
+func (s *networkSink) reportDropped() {
+	if n := atomic.SwapUint64(&s.dropped, 0); n > 0 {
+		fmt.Fprintf(os.Stderr, "%v log: dropped %d log entries to %s://%s\n", time.Now(), n, s.network, s.addr)
+	}
+}
+
+func (s *networkSink) closeConn() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *networkSink) send(entry []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.addr, networkDialTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	if _, err := s.conn.Write(entry); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// drop counts a dropped entry.
+func (s *networkSink) drop() {
+	atomic.AddUint64(&s.dropped, 1)
+	s.addPending(-1)
+}
+
+func (s *networkSink) addPending(delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	was := s.pending
+	s.pending += delta
+	switch {
+	case was == 0 && s.pending > 0:
+		s.drained = make(chan struct{})
+	case was > 0 && s.pending == 0:
+		close(s.drained)
+	}
+}
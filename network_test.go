@@ -0,0 +1,212 @@
+package log
+
+import (
+	"bufio"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// newStalledSink returns a network sink whose queue of the size is never consumed, like with a stalled remote.
+func newStalledSink(policy OverflowPolicy, size int) *networkSink {
+	s := &networkSink{network: "tcp", addr: "stalled", policy: policy, queue: make(chan []byte, size)}
+	s.drained = make(chan struct{})
+	close(s.drained)
+	return s
+}
+
+func queued(s *networkSink) []string {
+	var entries []string
+	for len(s.queue) > 0 {
+		entries = append(entries, string(<-s.queue))
+	}
+	return entries
+}
+
+func TestNetworkSinkDropPolicies(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   []string
+	}{
+		{DropNewest, []string{"1", "2"}},
+		{DropOldest, []string{"2", "3"}},
+	}
+	for _, tt := range tests {
+		s := newStalledSink(tt.policy, 2)
+		for _, entry := range []string{"1", "2", "3"} {
+			if _, err := s.Write([]byte(entry)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if s.dropped != 1 {
+			t.Errorf("policy %d: dropped %d entries, want 1", tt.policy, s.dropped)
+		}
+		got := queued(s)
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("policy %d: queued %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestNetworkSinkBlock(t *testing.T) {
+	s := newStalledSink(Block, 1)
+	s.Write([]byte("1"))
+
+	written := make(chan struct{})
+	go func() {
+		s.Write([]byte("2"))
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("Write did not block on the full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-s.queue
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("Write still blocked after the queue was consumed")
+	}
+	if s.dropped != 0 {
+		t.Errorf("dropped %d entries, want 0", s.dropped)
+	}
+}
+
+func TestNetworkSinkBlockClose(t *testing.T) {
+	s := newStalledSink(Block, 1)
+	s.stop = make(chan struct{})
+	s.Write([]byte("1"))
+
+	written := make(chan error)
+	go func() {
+		_, err := s.Write([]byte("2"))
+		written <- err
+	}()
+	close(s.stop)
+	select {
+	case err := <-written:
+		if err == nil {
+			t.Error("Write after the sending goroutine stopped succeeded")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write still blocked after the sending goroutine stopped")
+	}
+}
+
+func TestNetworkSinkSyncTimeout(t *testing.T) {
+	s := newStalledSink(DropNewest, 1)
+	s.Write([]byte("1"))
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		if err := s.drain(time.Millisecond); err == nil {
+			t.Fatal("drain of a stalled sink succeeded")
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines left after the timed out drains, %d before", after, before)
+	}
+
+	<-s.queue
+	s.addPending(-1)
+	if err := s.drain(time.Millisecond); err != nil {
+		t.Errorf("drain after the entry was sent: %v", err)
+	}
+}
+
+func TestNetworkSinkClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 100)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+
+	s := newNetworkSink("tcp", ln.Addr().String(), NetworkConfig{}, false)
+	const n = 50
+	for i := 0; i < n; i++ {
+		s.Write([]byte("entry\n"))
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-s.stopped:
+	default:
+		t.Error("the sending goroutine is still running after Close")
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if _, err := s.Write([]byte("late\n")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+
+	got := 0
+	for range lines {
+		got++
+	}
+	if got != n {
+		t.Errorf("received %d entries, want %d", got, n)
+	}
+}
+
+func TestNetworkSinkSynchronous(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	s := newNetworkSink("tcp", addr, NetworkConfig{}, true)
+	defer s.Close()
+	if s.queue != nil {
+		t.Error("a synchronous sink has a queue")
+	}
+	if _, err := s.Write([]byte("entry\n")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case line := <-lines:
+		if line != "entry" {
+			t.Errorf("received %q, want the entry", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the entry was not sent")
+	}
+
+	ln.Close()
+	down := newNetworkSink("tcp", addr, NetworkConfig{}, true)
+	defer down.Close()
+	if _, err := down.Write([]byte("entry\n")); err == nil {
+		t.Error("Write to a closed listener succeeded, want the error reported by the logging call")
+	}
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	presetECS = "ecs"
+	// ecsVersion is the version of the Elastic Common Schema the "ecs" preset follows
+	ecsVersion = "1.6.0"
+)
+
+// applyPreset adapts cfg to the preset named by conf.Preset. The encoder config is only adapted unless
+// conf.EncoderConfig replaces it.
+func applyPreset(cfg *zap.Config, conf Config) error {
+	switch conf.Preset {
+	case "":
+	case presetECS:
+		if conf.EncoderConfig == nil {
+			cfg.EncoderConfig.TimeKey = "@timestamp"
+			cfg.EncoderConfig.LevelKey = "log.level"
+			cfg.EncoderConfig.MessageKey = "message"
+			cfg.EncoderConfig.NameKey = "log.logger"
+			cfg.EncoderConfig.StacktraceKey = "error.stack_trace"
+			// the caller is split into the "log.origin" fields by the ecsCore
+			cfg.EncoderConfig.CallerKey = ""
+		}
+		cfg.InitialFields["ecs.version"] = ecsVersion
+	default:
+		return errors.Errorf("Unknown preset %q, expected ecs", conf.Preset)
+	}
+	return nil
+}
+
+// ecsFields rewrites the fields of an entry to the Elastic Common Schema: the caller is split into
+// the "log.origin.file.name", "log.origin.file.line" and "log.origin.function" fields and the errors
+// logged under the "error" key become the "error.message" and "error.type" fields.
+func ecsFields(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+	res := make([]zapcore.Field, 0, len(fields)+4)
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType && f.Key == "error" {
+			res = append(res,
+				zap.String("error.message", err.Error()),
+				zap.String("error.type", reflect.TypeOf(errors.Cause(err)).String()),
+			)
+			continue
+		}
+		res = append(res, f)
+	}
+	if ent.Caller.Defined {
+		res = append(res,
+			zap.String("log.origin.file.name", ent.Caller.File),
+			zap.Int("log.origin.file.line", ent.Caller.Line),
+		)
+		if ent.Caller.Function != "" {
+			res = append(res, zap.String("log.origin.function", ent.Caller.Function))
+		}
+	}
+	return ent, res
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestPresetECS(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Preset: "ecs"})
+	l.Errorw("failed", zap.Error(errors.New("disk full")))
+
+	entry := out.entries(t)[0]
+	for key, want := range map[string]interface{}{
+		"log.level":     "error",
+		"message":       "failed",
+		"ecs.version":   ecsVersion,
+		"error.message": "disk full",
+		"error.type":    "*errors.errorString",
+	} {
+		if entry[key] != want {
+			t.Errorf("%s = %v, want %v", key, entry[key], want)
+		}
+	}
+	for _, key := range []string{"@timestamp", "log.origin.file.line", "error.stack_trace"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("%s is missing", key)
+		}
+	}
+	if file, _ := entry["log.origin.file.name"].(string); !strings.HasSuffix(file, "preset_test.go") {
+		t.Errorf("log.origin.file.name = %q, want the test file", file)
+	}
+	if _, ok := entry["caller"]; ok {
+		t.Error("the caller is not split into the log.origin fields")
+	}
+
+	if _, err := New(Config{Encoding: "json", Preset: "gelf"}); err == nil {
+		t.Error("New accepted an unknown preset")
+	}
+}
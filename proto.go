@@ -0,0 +1,210 @@
+package log
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// The field numbers of logentry.proto.
+const (
+	protoEntryTime    = 1
+	protoEntryLevel   = 2
+	protoEntryLogger  = 3
+	protoEntryMessage = 4
+	protoEntryCaller  = 5
+	protoEntryStack   = 6
+	protoEntryField   = 7
+
+	protoFieldKey    = 1
+	protoFieldString = 2
+	protoFieldInt    = 3
+	protoFieldBool   = 4
+	protoFieldDouble = 5
+	protoFieldUint   = 6
+)
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+var protoPool = buffer.NewPool()
+
+// protoEncoder is a zapcore.Encoder which writes the entries as length-prefixed LogEntry protobuf messages,
+// see logentry.proto. The keys of the entry fields are given by the schema, so the EncoderConfig is not used.
+type protoEncoder struct {
+	// fields are the encoded Field messages added to the encoder
+	fields []byte
+	prefix string
+}
+
+func newProtoEncoder() *protoEncoder {
+	return &protoEncoder{}
+}
+
+func (e *protoEncoder) Clone() zapcore.Encoder {
+	return &protoEncoder{fields: append([]byte(nil), e.fields...), prefix: e.prefix}
+}
+
+func (e *protoEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	c := e.Clone().(*protoEncoder)
+	for _, f := range fields {
+		f.AddTo(c)
+	}
+
+	var msg []byte
+	if !ent.Time.IsZero() {
+		msg = appendProtoVarint(msg, protoEntryTime, uint64(ent.Time.UnixNano()))
+	}
+	msg = appendProtoString(msg, protoEntryLevel, levelName(ent.Level))
+	msg = appendProtoString(msg, protoEntryLogger, ent.LoggerName)
+	msg = appendProtoString(msg, protoEntryMessage, ent.Message)
+	if ent.Caller.Defined {
+		msg = appendProtoString(msg, protoEntryCaller, ent.Caller.String())
+	}
+	msg = appendProtoString(msg, protoEntryStack, ent.Stack)
+	msg = append(msg, c.fields...)
+
+	buf := protoPool.Get()
+	buf.Write(appendVarint(nil, uint64(len(msg))))
+	buf.Write(msg)
+	return buf, nil
+}
+
+// addField appends a Field message with the key and the value encoded by appendValue.
+func (e *protoEncoder) addField(key string, appendValue func([]byte) []byte) {
+	field := appendProtoString(nil, protoFieldKey, e.prefix+key)
+	field = appendValue(field)
+	e.fields = appendProtoBytes(e.fields, protoEntryField, field)
+}
+
+func (e *protoEncoder) addString(key, val string) {
+	e.addField(key, func(b []byte) []byte {
+		return appendProtoBytes(b, protoFieldString, []byte(val))
+	})
+}
+
+func (e *protoEncoder) addInt(key string, val int64) {
+	e.addField(key, func(b []byte) []byte {
+		return appendProtoVarint(b, protoFieldInt, uint64(val))
+	})
+}
+
+func (e *protoEncoder) addUint(key string, val uint64) {
+	e.addField(key, func(b []byte) []byte {
+		return appendProtoVarint(b, protoFieldUint, val)
+	})
+}
+
+func (e *protoEncoder) addJSON(key string, val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	e.addString(key, string(data))
+	return nil
+}
+
+func (e *protoEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.addJSON(key, m.Fields[key])
+}
+
+// AddObject flattens the object fields into dotted keys.
+func (e *protoEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	prefix := e.prefix
+	e.prefix += key + "."
+	err := obj.MarshalLogObject(e)
+	e.prefix = prefix
+	return err
+}
+
+func (e *protoEncoder) AddBinary(key string, val []byte) {
+	e.addString(key, base64.StdEncoding.EncodeToString(val))
+}
+func (e *protoEncoder) AddByteString(key string, val []byte) { e.addString(key, string(val)) }
+func (e *protoEncoder) AddBool(key string, val bool) {
+	e.addField(key, func(b []byte) []byte {
+		var v uint64
+		if val {
+			v = 1
+		}
+		return appendProtoVarint(b, protoFieldBool, v)
+	})
+}
+func (e *protoEncoder) AddComplex128(key string, val complex128) { e.addString(key, fmt.Sprint(val)) }
+func (e *protoEncoder) AddComplex64(key string, val complex64)   { e.addString(key, fmt.Sprint(val)) }
+func (e *protoEncoder) AddDuration(key string, val time.Duration) {
+	e.addInt(key, int64(val))
+}
+func (e *protoEncoder) AddFloat64(key string, val float64) {
+	e.addField(key, func(b []byte) []byte {
+		b = appendProtoTag(b, protoFieldDouble, wireFixed64)
+		var f [8]byte
+		binary.LittleEndian.PutUint64(f[:], math.Float64bits(val))
+		return append(b, f[:]...)
+	})
+}
+func (e *protoEncoder) AddFloat32(key string, val float32) { e.AddFloat64(key, float64(val)) }
+func (e *protoEncoder) AddInt(key string, val int)         { e.addInt(key, int64(val)) }
+func (e *protoEncoder) AddInt64(key string, val int64)     { e.addInt(key, val) }
+func (e *protoEncoder) AddInt32(key string, val int32)     { e.addInt(key, int64(val)) }
+func (e *protoEncoder) AddInt16(key string, val int16)     { e.addInt(key, int64(val)) }
+func (e *protoEncoder) AddInt8(key string, val int8)       { e.addInt(key, int64(val)) }
+func (e *protoEncoder) AddString(key, val string)          { e.addString(key, val) }
+func (e *protoEncoder) AddTime(key string, val time.Time)  { e.addInt(key, val.UnixNano()) }
+func (e *protoEncoder) AddUint(key string, val uint)       { e.addUint(key, uint64(val)) }
+func (e *protoEncoder) AddUint64(key string, val uint64)   { e.addUint(key, val) }
+func (e *protoEncoder) AddUint32(key string, val uint32)   { e.addUint(key, uint64(val)) }
+func (e *protoEncoder) AddUint16(key string, val uint16)   { e.addUint(key, uint64(val)) }
+func (e *protoEncoder) AddUint8(key string, val uint8)     { e.addUint(key, uint64(val)) }
+func (e *protoEncoder) AddUintptr(key string, val uintptr) { e.addUint(key, uint64(val)) }
+
+func (e *protoEncoder) AddReflected(key string, val interface{}) error {
+	return e.addJSON(key, val)
+}
+
+func (e *protoEncoder) OpenNamespace(key string) {
+	e.prefix += key + "."
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendProtoTag(b []byte, num, wireType int) []byte {
+	return appendVarint(b, uint64(num)<<3|uint64(wireType))
+}
+
+func appendProtoVarint(b []byte, num int, v uint64) []byte {
+	return appendVarint(appendProtoTag(b, num, wireVarint), v)
+}
+
+func appendProtoBytes(b []byte, num int, v []byte) []byte {
+	b = appendVarint(appendProtoTag(b, num, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendProtoString appends a string field unless it is empty, which is the default value in proto3.
+func appendProtoString(b []byte, num int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = appendVarint(appendProtoTag(b, num, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
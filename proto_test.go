@@ -0,0 +1,102 @@
+package log
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// protoMessage is a decoded protobuf message: the values of every field number, varints as uint64,
+// fixed64 as uint64 bits and bytes as strings.
+type protoMessage map[int][]interface{}
+
+func decodeProto(t *testing.T, b []byte) protoMessage {
+	t.Helper()
+	m := protoMessage{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad tag in %x", b)
+		}
+		b = b[n:]
+		num, wireType := int(tag>>3), int(tag&7)
+		switch wireType {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				t.Fatalf("bad varint in %x", b)
+			}
+			m[num] = append(m[num], v)
+			b = b[n:]
+		case wireFixed64:
+			m[num] = append(m[num], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				t.Fatalf("bad length in %x", b)
+			}
+			m[num] = append(m[num], string(b[n:n+int(l)]))
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", wireType)
+		}
+	}
+	return m
+}
+
+func TestProtoEncoder(t *testing.T) {
+	ent := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Unix(0, 1234),
+		Message: "hello",
+	}
+	buf, err := newProtoEncoder().EncodeEntry(ent, []zapcore.Field{
+		zap.String("s", "str"),
+		zap.Int64("i", -5),
+		zap.Uint64("u", math.MaxUint64),
+		zap.Bool("b", true),
+		zap.Float64("f", 1.5),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, n := binary.Uvarint(b)
+	if int(l) != len(b)-n {
+		t.Fatalf("length prefix %d, want %d", l, len(b)-n)
+	}
+
+	msg := decodeProto(t, b[n:])
+	if got := msg[protoEntryTime][0].(uint64); got != 1234 {
+		t.Errorf("time = %d, want 1234", got)
+	}
+	if got := msg[protoEntryMessage][0].(string); got != "hello" {
+		t.Errorf("message = %q, want %q", got, "hello")
+	}
+
+	fields := map[string]protoMessage{}
+	for _, f := range msg[protoEntryField] {
+		field := decodeProto(t, []byte(f.(string)))
+		fields[field[protoFieldKey][0].(string)] = field
+	}
+	if got := fields["s"][protoFieldString][0].(string); got != "str" {
+		t.Errorf("s = %q, want %q", got, "str")
+	}
+	if got := int64(fields["i"][protoFieldInt][0].(uint64)); got != -5 {
+		t.Errorf("i = %d, want -5", got)
+	}
+	if got := fields["u"][protoFieldUint][0].(uint64); got != math.MaxUint64 {
+		t.Errorf("u = %d, want %d", got, uint64(math.MaxUint64))
+	}
+	if got := fields["b"][protoFieldBool][0].(uint64); got != 1 {
+		t.Errorf("b = %d, want 1", got)
+	}
+	if got := math.Float64frombits(fields["f"][protoFieldDouble][0].(uint64)); got != 1.5 {
+		t.Errorf("f = %v, want 1.5", got)
+	}
+}
@@ -0,0 +1,31 @@
+package log
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Query logs an SQL query executed in the context with the "sql", "args_count" and "duration" (in fractional
+// milliseconds) fields, at DEBUG level if it succeeded or at ERROR level with the "error" field if it failed.
+//
+// The query is truncated to Config.MaxQueryLength bytes if set, without splitting a UTF-8 sequence. The argument
+// values are only logged in the "args" field if Config.LogQueryArgs is set, which is meant for local debugging.
+func (l *logger) Query(ctx context.Context, sql string, args []interface{}, d time.Duration, err error) {
+	if l.maxQueryLength > 0 {
+		sql = truncate(sql, l.maxQueryLength)
+	}
+
+	fields := []interface{}{"sql", sql, "args_count", len(args), "duration", float64(d.Microseconds()) / 1000}
+	if l.logQueryArgs {
+		fields = append(fields, "args", args)
+	}
+
+	logger := l.With(ctx).skipCaller()
+	if err != nil {
+		logger.Errorw("SQL query failed", append(fields, zap.Error(err))...)
+		return
+	}
+	logger.Debugw("SQL query", fields...)
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestQuery(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "debug", MaxQueryLength: 29})
+	// the limit falls into the two-byte "é"
+	l.Query(context.Background(), "SELECT * FROM t WHERE name='éé'", []interface{}{1, 2}, 3500*time.Microsecond, nil)
+	l.Query(context.Background(), "SELECT 1", nil, time.Millisecond, errors.New("conn reset"))
+
+	entries := out.entries(t)
+	sql := entries[0]["sql"].(string)
+	if !utf8.ValidString(sql) || sql != "SELECT * FROM t WHERE name='"+truncatedSuffix {
+		t.Errorf("sql = %q, want the query cut before the split rune", sql)
+	}
+	if entries[0]["level"] != "debug" || entries[0]["args_count"] != float64(2) || entries[0]["duration"] != 3.5 {
+		t.Errorf("entry = %v, want a debug entry with args_count 2 and duration 3.5", entries[0])
+	}
+	if _, ok := entries[0]["args"]; ok {
+		t.Error("args logged without Config.LogQueryArgs")
+	}
+	if entries[1]["level"] != "error" || entries[1]["error"] != "conn reset" {
+		t.Errorf("entry = %v, want an error entry with the error", entries[1])
+	}
+}
+
+func TestQueryArgs(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Level: "debug", LogQueryArgs: true})
+	l.Query(context.Background(), "SELECT ?", []interface{}{"a"}, 0, nil)
+
+	args, _ := out.entries(t)[0]["args"].([]interface{})
+	if len(args) != 1 || args[0] != "a" {
+		t.Errorf("args = %v, want [a]", args)
+	}
+}
@@ -0,0 +1,15 @@
+package log
+
+import "go.uber.org/zap"
+
+// WithAttempt returns a logger based off l for an attempt of a retried operation. Every log message
+// generated by the logger gets the "attempt" (starting from 1) and "max_attempts" fields.
+func (l *logger) WithAttempt(attempt, maxAttempts int) *logger {
+	return l.with("attempt", attempt, "max_attempts", maxAttempts)
+}
+
+// RetriesExhausted logs at ERROR level the summary of a retried operation which failed in all of its
+// attempts, with the total number of attempts in the "attempts" field and the last error in the "error" field.
+func (l *logger) RetriesExhausted(attempts int, err error) {
+	l.skipCaller().Errorw("Retry attempts exhausted", "attempts", attempts, zap.Error(err))
+}
@@ -0,0 +1,29 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRetries(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	for attempt := 1; attempt <= 2; attempt++ {
+		l.WithAttempt(attempt, 2).Warn("attempt failed")
+	}
+	l.RetriesExhausted(2, errors.New("unavailable"))
+
+	entries := out.entries(t)
+	for i, e := range entries[:2] {
+		if e["attempt"] != float64(i+1) || e["max_attempts"] != float64(2) {
+			t.Errorf("entry %d = %v, want attempt %d of 2", i, e, i+1)
+		}
+	}
+	summary := entries[2]
+	if summary["level"] != "error" || summary["attempts"] != float64(2) || summary["error"] != "unavailable" {
+		t.Errorf("summary = %v, want an ERROR entry with 2 attempts and the last error", summary)
+	}
+	if caller, _ := summary["caller"].(string); !strings.Contains(caller, "retry_test.go") {
+		t.Errorf("caller = %q, want the line calling RetriesExhausted", caller)
+	}
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"strings"
+	"sync"
+)
+
+// ring is a zapcore.WriteSyncer which keeps the most recent entries written to it.
+type ring struct {
+	mu      sync.Mutex
+	entries []string
+	next    int
+	full    bool
+}
+
+func newRing(size int) *ring {
+	return &ring{entries: make([]string, size)}
+}
+
+func (r *ring) Write(p []byte) (int, error) {
+	entry := strings.TrimSuffix(string(p), "\n")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = entry
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	return len(p), nil
+}
+
+func (r *ring) Sync() error {
+	return nil
+}
+
+// recent returns up to n most recent entries, the oldest first.
+func (r *ring) recent(n int) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	size := r.next
+	if r.full {
+		size = len(r.entries)
+	}
+	if n > size {
+		n = size
+	}
+
+	res := make([]string, n)
+	for i := range res {
+		res[i] = r.entries[(r.next-n+i+len(r.entries))%len(r.entries)]
+	}
+	return res
+}
+
+// Recent returns up to n most recent entries written by the logger, rendered by its encoder, the oldest first.
+// It requires Config.RingBufferSize to be set, which limits the number of entries kept; otherwise it returns nil.
+func (l *logger) Recent(n int) []string {
+	if l.ring == nil || n <= 0 {
+		return nil
+	}
+	return l.ring.recent(n)
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := newRing(3)
+	if got := r.recent(5); len(got) != 0 {
+		t.Errorf("recent of an empty ring = %v", got)
+	}
+	for i := 1; i <= 2; i++ {
+		fmt.Fprintf(r, "e%d\n", i)
+	}
+	if got, want := r.recent(5), []string{"e1", "e2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("recent before wraparound = %v, want %v", got, want)
+	}
+	for i := 3; i <= 7; i++ {
+		fmt.Fprintf(r, "e%d\n", i)
+	}
+	if got, want := r.recent(5), []string{"e5", "e6", "e7"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("recent after wraparound = %v, want %v", got, want)
+	}
+	if got, want := r.recent(2), []string{"e6", "e7"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("recent(2) = %v, want %v", got, want)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	l, _ := newBufferLogger(t, Config{RingBufferSize: 2})
+	l.Info("first")
+	l.Info("second")
+	l.Debug("disabled")
+	l.Info("third")
+
+	got := l.Recent(10)
+	if len(got) != 2 || !strings.Contains(got[0], `"second"`) || !strings.Contains(got[1], `"third"`) {
+		t.Errorf("Recent(10) = %v, want the second and the third entries", got)
+	}
+	if got := NewByDefault().Recent(10); got != nil {
+		t.Errorf("Recent without a ring buffer = %v, want nil", got)
+	}
+}
@@ -0,0 +1,39 @@
+package log
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// StartRuntimeStats starts a goroutine which logs at DEBUG level a "Runtime stats" message every interval,
+// with the "goroutines", "heap_alloc" (in bytes) and "num_gc" fields, until ctx is canceled. It is meant for
+// diagnosing leaks where no metrics system is available.
+func (l *logger) StartRuntimeStats(ctx context.Context, interval time.Duration) {
+	logger := l.With(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var mem runtime.MemStats
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
+					// skip the stop-the-world ReadMemStats while DEBUG is disabled
+					continue
+				}
+				runtime.ReadMemStats(&mem)
+				logger.Debugw("Runtime stats",
+					"goroutines", runtime.NumGoroutine(),
+					"heap_alloc", mem.HeapAlloc,
+					"num_gc", mem.NumGC,
+				)
+			}
+		}
+	}()
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestStartRuntimeStats(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewWithCore(core)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l.StartRuntimeStats(ctx, time.Millisecond)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	entries := logs.All()
+	if len(entries) == 0 {
+		t.Fatal("no runtime stats logged")
+	}
+	e := entries[0]
+	fields := e.ContextMap()
+	if e.Message != "Runtime stats" || fields["goroutines"].(int64) < 2 || fields["heap_alloc"].(uint64) == 0 {
+		t.Errorf("entry = %q %v, want the runtime stats", e.Message, fields)
+	}
+}
@@ -0,0 +1,208 @@
+package log
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	samplingKeyField = "_samplingKey"
+	samplerCounters  = 4096
+)
+
+// SamplingConfig sets a sampling strategy for the logger. It is ignored if Config.Development is set.
+//
+// Within every Tick the first Initial entries with the same level, message and sampling key are logged,
+// after that only every Thereafter-th one is. The sampling key is taken from the context passed to With
+// (recorded via WithSamplingKey()), so e.g. every tenant is sampled independently.
+//
+// If Probability is set, the entries are sampled statistically instead: every entry is logged with
+// the given probability regardless of its message, except the entries at ERROR or a higher level,
+// which are always logged.
+//
+// The entries carrying an error field (e.g. added by zap.Error or the "error" key of the sugared logger) are never
+// sampled out.
+type SamplingConfig struct {
+	Initial    int
+	Thereafter int
+	// Tick is the sampling interval, one second if zero
+	Tick        time.Duration
+	Probability float64
+}
+
+// WithSamplingKey returns a context which makes loggers derived via With sample their entries separately
+// from the entries logged with other keys.
+func WithSamplingKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, samplingKeyKey, key)
+}
+
+// samplingKey returns a field which carries the sampling key to the sampler core without being encoded.
+func samplingKey(key string) zap.Field {
+	return zap.Field{Key: samplingKeyField, Type: zapcore.SkipType, String: key}
+}
+
+type counter struct {
+	resetAt int64
+	count   uint64
+}
+
+func (c *counter) incCheckReset(t time.Time, tick time.Duration) uint64 {
+	tn := t.UnixNano()
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > tn {
+		return atomic.AddUint64(&c.count, 1)
+	}
+
+	atomic.StoreUint64(&c.count, 1)
+	if !atomic.CompareAndSwapInt64(&c.resetAt, resetAt, tn+tick.Nanoseconds()) {
+		// another goroutine has reset the counter concurrently
+		return atomic.AddUint64(&c.count, 1)
+	}
+	return 1
+}
+
+type counters [samplerCounters]counter
+
+func (cs *counters) get(lvl zapcore.Level, msg, key string) *counter {
+	hash := fnv32a(fnvOffset^uint32(uint8(lvl)), msg)
+	hash = fnv32a(hash, key)
+	return &cs[hash%samplerCounters]
+}
+
+const fnvOffset = 2166136261
+
+// fnv32a continues the "hash/fnv" FNV-1a hash with s without allocations.
+func fnv32a(hash uint32, s string) uint32 {
+	const prime32 = 16777619
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= prime32
+	}
+	return hash
+}
+
+// samplingPolicy is the sampling state of a SamplingConfig.
+type samplingPolicy struct {
+	counts     *counters
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+
+	// probability enables the statistical sampling if positive
+	probability float64
+}
+
+func newSamplingPolicy(conf SamplingConfig) *samplingPolicy {
+	tick := conf.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	return &samplingPolicy{
+		counts:      &counters{},
+		tick:        tick,
+		first:       uint64(conf.Initial),
+		thereafter:  uint64(conf.Thereafter),
+		probability: conf.Probability,
+	}
+}
+
+// drop tells whether the entry logged with the sampling key is sampled out.
+func (p *samplingPolicy) drop(ent zapcore.Entry, key string) bool {
+	if p.probability > 0 {
+		return ent.Level < zapcore.ErrorLevel && rand.Float64() >= p.probability
+	}
+
+	n := p.counts.get(ent.Level, ent.Message, key).incCheckReset(ent.Time, p.tick)
+	return n > p.first && (p.thereafter == 0 || (n-p.first)%p.thereafter != 0)
+}
+
+// samplingSwitch holds the sampling policy shared by the sampler cores of a logger, which SetSampling replaces.
+type samplingSwitch struct {
+	// policy is the *samplingPolicy in use, nil if the sampling is disabled
+	policy atomic.Value
+}
+
+func newSamplingSwitch(conf *SamplingConfig) *samplingSwitch {
+	s := &samplingSwitch{}
+	s.set(conf)
+	return s
+}
+
+func (s *samplingSwitch) set(conf *SamplingConfig) {
+	var p *samplingPolicy
+	if conf != nil {
+		p = newSamplingPolicy(*conf)
+	}
+	s.policy.Store(p)
+}
+
+func (s *samplingSwitch) load() *samplingPolicy {
+	return s.policy.Load().(*samplingPolicy)
+}
+
+// sampler is a zapcore.Core which samples entries by their level, message and sampling key
+// following the policy of its switch.
+type sampler struct {
+	zapcore.Core
+	sw  *samplingSwitch
+	key string
+	// hasError is set if the fields added by With carry an error
+	hasError bool
+}
+
+func newSampler(core zapcore.Core, sw *samplingSwitch) *sampler {
+	return &sampler{Core: core, sw: sw}
+}
+
+func (s *sampler) With(fields []zapcore.Field) zapcore.Core {
+	c := *s
+	for _, f := range fields {
+		if f.Type == zapcore.SkipType && f.Key == samplingKeyField {
+			c.key = f.String
+		}
+	}
+	c.hasError = c.hasError || hasErrorField(fields)
+	c.Core = s.Core.With(fields)
+	return &c
+}
+
+// Check defers the sampling to Write, since the fields of an entry are not known before it is written.
+func (s *sampler) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s.Enabled(ent.Level) {
+		return ce.AddCore(ent, s)
+	}
+	return ce
+}
+
+func (s *sampler) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if p := s.sw.load(); p != nil && !s.hasError && !hasErrorField(fields) && p.drop(ent, s.key) {
+		return nil
+	}
+	return s.Core.Write(ent, fields)
+}
+
+// SetSampling replaces the sampling of the logger built by New and all the loggers derived from it,
+// e.g. to sample more aggressively during an incident; nil disables the sampling. The sampling counters
+// start from zero.
+func (l *logger) SetSampling(conf *SamplingConfig) error {
+	if l.sampling == nil {
+		return errors.New("Can not set sampling of a logger not created by New")
+	}
+	l.sampling.set(conf)
+	return nil
+}
+
+func hasErrorField(fields []zapcore.Field) bool {
+	for _, f := range fields {
+		if f.Type == zapcore.ErrorType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSamplingKey(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Sampling: &SamplingConfig{Initial: 1, Tick: time.Hour}})
+	for _, key := range []string{"a", "b"} {
+		logger := l.With(WithSamplingKey(context.Background(), key))
+		for i := 0; i < 3; i++ {
+			logger.Info("m")
+		}
+	}
+	if n := len(out.entries(t)); n != 2 {
+		t.Errorf("%d entries logged, want the first one of every sampling key", n)
+	}
+}
+
+func TestSamplingProbability(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Sampling: &SamplingConfig{Probability: 0.5}})
+	const n = 2000
+	for i := 0; i < n; i++ {
+		l.Info("m")
+	}
+	if got := len(out.entries(t)); got < n/2-200 || got > n/2+200 {
+		t.Errorf("%d of %d entries logged with the probability 0.5", got, n)
+	}
+
+	out.Reset()
+	for i := 0; i < 100; i++ {
+		l.Error("m")
+	}
+	if got := len(out.entries(t)); got != 100 {
+		t.Errorf("%d of 100 ERROR entries logged, want all", got)
+	}
+}
+
+func TestSamplingKeepsErrors(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Sampling: &SamplingConfig{Initial: 1, Tick: time.Hour}})
+	for i := 0; i < 3; i++ {
+		l.Infow("m", "error", errors.New("failed"))
+	}
+	withErr := l.With(context.Background(), zap.Error(errors.New("failed")))
+	for i := 0; i < 3; i++ {
+		withErr.Info("m")
+	}
+	for i := 0; i < 3; i++ {
+		l.Info("m")
+	}
+	if n := len(out.entries(t)); n != 7 {
+		t.Errorf("%d entries logged, want all 6 entries with an error and the first one without", n)
+	}
+}
+
+func TestSamplingDisabledInDevelopment(t *testing.T) {
+	l, out := newBufferLogger(t, Config{Development: true, Sampling: &SamplingConfig{Initial: 1, Tick: time.Hour}})
+	for i := 0; i < 3; i++ {
+		l.Info("m")
+	}
+	if n := len(out.entries(t)); n != 3 {
+		t.Errorf("%d entries logged in development, want all 3", n)
+	}
+}
+
+func TestSetSampling(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	if err := l.SetSampling(&SamplingConfig{Initial: 1, Tick: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		l.Info("m")
+	}
+	if err := l.SetSampling(nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		l.Info("m")
+	}
+	if n := len(out.entries(t)); n != 4 {
+		t.Errorf("%d entries logged, want 1 sampled and 3 unsampled", n)
+	}
+
+	if err := NewByDefault().SetSampling(nil); err == nil {
+		t.Error("SetSampling of a logger not created by New succeeded")
+	}
+}
+
+// TestSetSamplingConcurrent is meant to be run with -race.
+func TestSetSamplingConcurrent(t *testing.T) {
+	l, _ := newBufferLogger(t, Config{DisableLevelAudit: true})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				switch j % 3 {
+				case 0:
+					l.SetSampling(&SamplingConfig{Initial: j, Thereafter: 2})
+				case 1:
+					l.SetLevel("debug")
+				default:
+					l.With(context.Background(), "worker", i).Info("m")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type sentinel struct {
+	name string
+	err  error
+}
+
+var sentinels struct {
+	mu   sync.RWMutex
+	errs []sentinel
+}
+
+// RegisterSentinel registers a sentinel error, like sql.ErrNoRows, under the name MatchedErrors reports it by.
+// The names are reported in the order of registration.
+func RegisterSentinel(name string, err error) {
+	sentinels.mu.Lock()
+	defer sentinels.mu.Unlock()
+
+	sentinels.errs = append(sentinels.errs, sentinel{name: name, err: err})
+}
+
+// MatchedErrors constructs a "matched_errors" field with the names of the sentinels registered by RegisterSentinel
+// which err matches by errors.Is, so the alerts can be routed on specific error classes. The field is skipped
+// if err matches none. WithError adds the field as well.
+func MatchedErrors(err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+
+	sentinels.mu.RLock()
+	defer sentinels.mu.RUnlock()
+
+	var names []string
+	for _, s := range sentinels.errs {
+		if errors.Is(err, s.err) {
+			names = append(names, s.name)
+		}
+	}
+	if names == nil {
+		return zap.Skip()
+	}
+	return zap.Strings("matched_errors", names)
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	errSentinelNotFound = errors.New("not found")
+	errSentinelOther    = errors.New("other")
+)
+
+func init() {
+	RegisterSentinel("not_found", errSentinelNotFound)
+	RegisterSentinel("other", errSentinelOther)
+}
+
+func TestMatchedErrors(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	MatchedErrors(fmt.Errorf("loading user: %w", errSentinelNotFound)).AddTo(enc)
+	if got := enc.Fields["matched_errors"]; !reflect.DeepEqual(got, []interface{}{"not_found"}) {
+		t.Errorf("matched_errors = %v, want [not_found]", got)
+	}
+	if f := MatchedErrors(errors.New("unknown")); f.Type != zapcore.SkipType {
+		t.Error("the field is not skipped for an error matching no sentinel")
+	}
+	if f := MatchedErrors(nil); f.Type != zapcore.SkipType {
+		t.Error("the field is not skipped for nil")
+	}
+
+	l, out := newBufferLogger(t, Config{})
+	l.WithError(errSentinelOther).Error("m")
+	if got := out.entries(t)[0]["matched_errors"]; !reflect.DeepEqual(got, []interface{}{"other"}) {
+		t.Errorf("WithError matched_errors = %v, want [other]", got)
+	}
+}
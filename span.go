@@ -0,0 +1,11 @@
+//go:build !otel
+// +build !otel
+
+package log
+
+import "context"
+
+// spanFromContext is a no-op unless the package is built with the "otel" tag.
+func spanFromContext(ctx context.Context) spanEventer {
+	return nil
+}
@@ -0,0 +1,54 @@
+//go:build otel
+// +build otel
+
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// spanFromContext returns the recording OpenTelemetry span of the context, or nil if there is none.
+func spanFromContext(ctx context.Context) spanEventer {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+	return otelSpan{span}
+}
+
+type otelSpan struct {
+	trace.Span
+}
+
+// addEvent adds the entry as an event named by its message, with the level and the fields as the attributes.
+func (s otelSpan) addEvent(ent zapcore.Entry, fields []zapcore.Field) {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(enc.Fields)+1)
+	attrs = append(attrs, attribute.String("level", levelName(ent.Level)))
+	for key, val := range enc.Fields {
+		switch v := val.(type) {
+		case string:
+			attrs = append(attrs, attribute.String(key, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(key, v))
+		case int:
+			attrs = append(attrs, attribute.Int(key, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(key, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(key, v))
+		default:
+			attrs = append(attrs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	s.AddEvent(ent.Message, trace.WithAttributes(attrs...))
+}
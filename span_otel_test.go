@@ -0,0 +1,46 @@
+//go:build otel
+// +build otel
+
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingSpan is a recording trace.Span which records the names of the events added to it.
+type recordingSpan struct {
+	trace.Span
+	events []string
+	attrs  []map[string]string
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) AddEvent(name string, opts ...trace.EventOption) {
+	attrs := map[string]string{}
+	conf := trace.NewEventConfig(opts...)
+	for _, kv := range conf.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	s.events = append(s.events, name)
+	s.attrs = append(s.attrs, attrs)
+}
+
+func TestSpanEvents(t *testing.T) {
+	span := &recordingSpan{Span: trace.SpanFromContext(context.Background())}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	l, _ := newBufferLogger(t, Config{SpanEventLevel: "info"})
+	l.With(ctx).Infow("Order placed", "items", 3)
+	l.With(ctx).Debug("disabled")
+
+	if len(span.events) != 1 || span.events[0] != "Order placed" {
+		t.Fatalf("events = %v, want the INFO entry", span.events)
+	}
+	if a := span.attrs[0]; a["level"] != "info" || a["items"] != "3" {
+		t.Errorf("attributes = %v, want the level and the fields", a)
+	}
+}
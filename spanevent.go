@@ -0,0 +1,56 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const spanField = "_span"
+
+// spanEventer adds the entries as events to a trace span.
+type spanEventer interface {
+	addEvent(ent zapcore.Entry, fields []zapcore.Field)
+}
+
+// spanFieldOf returns a field which carries the span to the spanEventCore without being encoded.
+func spanFieldOf(span spanEventer) zap.Field {
+	return zap.Field{Key: spanField, Type: zapcore.SkipType, Interface: span}
+}
+
+// spanEventCore is a zapcore.Core which adds the entries enabled by enab as events to the span
+// carried by the fields added by With, see Config.SpanEventLevel.
+type spanEventCore struct {
+	zapcore.Core
+	enab zapcore.LevelEnabler
+	span spanEventer
+	// fields are the fields added by With, which are the event attributes besides the entry fields
+	fields []zapcore.Field
+}
+
+func (c *spanEventCore) With(fields []zapcore.Field) zapcore.Core {
+	nc := *c
+	for _, f := range fields {
+		switch {
+		case f.Type == zapcore.SkipType && f.Key == spanField:
+			nc.span = f.Interface.(spanEventer)
+		case f.Type != zapcore.SkipType:
+			nc.fields = append(nc.fields[:len(nc.fields):len(nc.fields)], f)
+		}
+	}
+	nc.Core = c.Core.With(fields)
+	return &nc
+}
+
+func (c *spanEventCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *spanEventCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.span != nil && c.enab.Enabled(ent.Level) {
+		c.span.addEvent(ent, append(c.fields[:len(c.fields):len(c.fields)], fields...))
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// recordingEventer is a spanEventer recording the events.
+type recordingEventer struct {
+	events []string
+	fields []map[string]interface{}
+}
+
+func (r *recordingEventer) addEvent(ent zapcore.Entry, fields []zapcore.Field) {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	r.events = append(r.events, ent.Message)
+	r.fields = append(r.fields, enc.Fields)
+}
+
+func TestSpanEventCore(t *testing.T) {
+	l, out := newBufferLogger(t, Config{SpanEventLevel: "warn"})
+	span := &recordingEventer{}
+	sl := l.With(context.Background(), spanFieldOf(span), "user", "alice")
+	sl.Info("info")
+	sl.Warnw("warn", "attempt", 2)
+	l.Warn("without span")
+
+	if len(span.events) != 1 || span.events[0] != "warn" {
+		t.Fatalf("events = %v, want the WARN entry only", span.events)
+	}
+	if f := span.fields[0]; f["user"] != "alice" || f["attempt"] != int64(2) {
+		t.Errorf("event fields = %v, want the fields added by With and the entry fields", f)
+	}
+	if n := len(out.entries(t)); n != 3 {
+		t.Errorf("%d entries written, want all 3", n)
+	}
+}
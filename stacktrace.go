@@ -0,0 +1,57 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// stacktraceMaxDepth limits the number of the frames captured by callerStack
+const stacktraceMaxDepth = 64
+
+// stacktraceIf returns a rewrite adding the "stacktrace" field to the entries with an error field
+// matching the predicate.
+func stacktraceIf(match func(error) bool) func(zapcore.Entry, []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+	return func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+		for _, f := range fields {
+			if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType && match(err) {
+				return ent, append(fields, zap.String("stacktrace", callerStack()))
+			}
+		}
+		return ent, fields
+	}
+}
+
+// callerStack formats the stack of the current goroutine like zap does, but starting from the first frame
+// outside zap and this package, i.e. from the logging call.
+func callerStack() string {
+	pcs := make([]uintptr, stacktraceMaxDepth)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	var b strings.Builder
+	skipping := true
+	for {
+		frame, more := frames.Next()
+		if skipping {
+			pkg := funcPackage(frame.Function)
+			skipping = pkg == "github.com/minipkg/log" || strings.HasPrefix(pkg, "go.uber.org/zap")
+		}
+		if !skipping {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function)
+			b.WriteString("\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
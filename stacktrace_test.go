@@ -0,0 +1,28 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+var errUnexpected = errors.New("unexpected")
+
+func TestStacktraceIf(t *testing.T) {
+	l, out := newBufferLogger(t, Config{StacktraceIf: func(err error) bool {
+		return errors.Is(err, errUnexpected)
+	}})
+	l.Warnw("matching", zap.Error(errUnexpected))
+	l.Warnw("other", zap.Error(errors.New("expected")))
+
+	entries := out.entries(t)
+	// the frames of this package, including the test, are skipped
+	if st, _ := entries[0]["stacktrace"].(string); !strings.Contains(st, "testing.tRunner") {
+		t.Errorf("stacktrace = %q, want the stack of the logging call", st)
+	}
+	if _, ok := entries[1]["stacktrace"]; ok {
+		t.Error("stacktrace added for an error not matching the predicate")
+	}
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Redacted is logged instead of the values of the sensitive fields.
+const Redacted = "[REDACTED]"
+
+// maxStructDepth is the number of the nested structs flattened by WithStruct, the deeper ones are logged as values.
+const maxStructDepth = 10
+
+// cycleValue is logged instead of a pointer back to a struct being flattened.
+const cycleValue = "[CYCLE]"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// WithStruct returns a logger based off l and decorated with the given context like With, and with the exported
+// fields of the struct v (or a pointer to it) as arguments.
+//
+// The fields are named after the `log:"name"` struct tag or the field name, `log:"-"` skips a field and
+// `redact:"true"` replaces its value by "[REDACTED]". Nested structs are flattened into dotted keys like "user.id",
+// the fields of embedded structs are added as they are. The structs nested deeper than 10 levels are logged as
+// values, and a pointer back to a struct being flattened, like a parent pointer, is logged as "[CYCLE]".
+func (l *logger) WithStruct(ctx context.Context, v interface{}) *logger {
+	return l.With(ctx, structFields("", reflect.ValueOf(v), 0, map[structVisit]bool{})...)
+}
+
+// structVisit identifies a struct pointer followed by structFields, to detect the cycles.
+type structVisit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// structFields returns the fields of the struct v nested at the depth. visited holds the pointers followed to reach v.
+func structFields(prefix string, v reflect.Value, depth int, visited map[structVisit]bool) []interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			visit := structVisit{v.Pointer(), v.Type()}
+			visited[visit] = true
+			defer delete(visited, visit)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []interface{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		name := sf.Name
+		if tag := sf.Tag.Get("log"); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		key := prefix + name
+
+		if sf.Tag.Get("redact") == "true" {
+			fields = append(fields, zap.String(key, Redacted))
+			continue
+		}
+
+		fv := v.Field(i)
+		if isNested(fv) {
+			switch {
+			case fv.Kind() == reflect.Ptr && !fv.IsNil() && visited[structVisit{fv.Pointer(), fv.Type()}]:
+				fields = append(fields, zap.String(key, cycleValue))
+			case depth >= maxStructDepth:
+				if sf.PkgPath == "" {
+					fields = append(fields, zap.Any(key, fv.Interface()))
+				}
+			case sf.Anonymous && sf.Tag.Get("log") == "":
+				fields = append(fields, structFields(prefix, fv, depth+1, visited)...)
+			default:
+				fields = append(fields, structFields(key+".", fv, depth+1, visited)...)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		fields = append(fields, zap.Any(key, fv.Interface()))
+	}
+	return fields
+}
+
+// isNested tells if the value is a struct (or a pointer to it) to be flattened rather than logged as a value.
+func isNested(v reflect.Value) bool {
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
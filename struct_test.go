@@ -0,0 +1,88 @@
+package log
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type structAudit struct {
+	Created string
+}
+
+type structUser struct {
+	ID      int    `log:"id"`
+	Name    string `log:"name"`
+	Token   string `redact:"true"`
+	Skipped string `log:"-"`
+	secret  string
+	Address struct {
+		City string `log:"city"`
+	} `log:"address"`
+	*structAudit
+}
+
+func TestWithStruct(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	u := structUser{ID: 7, Name: "alice", Token: "t0k3n", Skipped: "x", secret: "s", structAudit: &structAudit{Created: "today"}}
+	u.Address.City = "Paris"
+	l.WithStruct(context.Background(), &u).Info("m")
+
+	entry := out.entries(t)[0]
+	for key, want := range map[string]interface{}{
+		"id":           float64(7),
+		"name":         "alice",
+		"Token":        Redacted,
+		"address.city": "Paris",
+		"Created":      "today",
+	} {
+		if entry[key] != want {
+			t.Errorf("%s = %v, want %v", key, entry[key], want)
+		}
+	}
+	for _, key := range []string{"Skipped", "secret"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("%s is logged", key)
+		}
+	}
+}
+
+type structNode struct {
+	Name   string `log:"name"`
+	Parent *structNode
+}
+
+func TestWithStructCycle(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	n := structNode{Name: "root"}
+	n.Parent = &n
+	l.WithStruct(context.Background(), &n).Info("m")
+	l.WithStruct(context.Background(), n).Info("m")
+
+	entries := out.entries(t)
+	if e := entries[0]; e["name"] != "root" || e["Parent"] != cycleValue {
+		t.Errorf("entry = %v, want the parent pointer logged as a cycle", e)
+	}
+	if e := entries[1]; e["Parent.name"] != "root" || e["Parent.Parent"] != cycleValue {
+		t.Errorf("entry = %v, want the copy flattened once and then the cycle", e)
+	}
+}
+
+func TestWithStructDepth(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	n := &structNode{Name: "0"}
+	for i := 1; i <= maxStructDepth+2; i++ {
+		n = &structNode{Name: strconv.Itoa(i), Parent: n}
+	}
+	l.WithStruct(context.Background(), n).Info("m")
+
+	entry := out.entries(t)[0]
+	key := strings.Repeat("Parent.", maxStructDepth)
+	if entry[key+"name"] != "2" {
+		t.Errorf("%sname = %v, want the struct at the maximum depth flattened", key, entry[key+"name"])
+	}
+	if parent, _ := entry[key+"Parent"].(map[string]interface{}); parent["Name"] != "1" {
+		t.Errorf("%sParent = %v, want the deeper struct logged as a value", key, entry[key+"Parent"])
+	}
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sync flushes any buffered log entries. A panic of a misbehaving sink is recovered and returned as an error.
+func (l *logger) Sync() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("Sync panicked: %v", r)
+		}
+	}()
+	return l.SugaredLogger.Sync()
+}
+
+// SyncContext is like Sync but returns an error wrapping the context error if ctx is done before Sync completes,
+// e.g. to flush the logs at the end of a request without delaying the response indefinitely. The abandoned Sync
+// keeps running in the background.
+func (l *logger) SyncContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Sync()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "Can not sync logger")
+	}
+}
+
+// SyncTimeout is like Sync but returns an error wrapping context.DeadlineExceeded if Sync does not
+// complete within d, so a blocked sink can not hang the shutdown. The abandoned Sync keeps running
+// in the background.
+func (l *logger) SyncTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	if err := l.SyncContext(ctx); err != nil {
+		if errors.Cause(err) == context.DeadlineExceeded {
+			return errors.Wrapf(context.DeadlineExceeded, "Can not sync logger within %s", d)
+		}
+		return err
+	}
+	return nil
+}
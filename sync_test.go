@@ -0,0 +1,83 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// panicSyncer is a zapcore.WriteSyncer whose Sync panics.
+type panicSyncer struct {
+	bufferSyncer
+}
+
+func (s *panicSyncer) Sync() error {
+	panic("sync")
+}
+
+// blockingSyncer is a zapcore.WriteSyncer whose Sync blocks until release is closed.
+type blockingSyncer struct {
+	bufferSyncer
+	release chan struct{}
+}
+
+func (s *blockingSyncer) Sync() error {
+	<-s.release
+	return nil
+}
+
+func TestSyncRecoversPanic(t *testing.T) {
+	l, err := NewWithSyncer(&panicSyncer{}, Config{Encoding: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Sync(); err == nil {
+		t.Error("Sync returned no error for a panicking sink")
+	}
+}
+
+func TestSyncTimeout(t *testing.T) {
+	out := &blockingSyncer{release: make(chan struct{})}
+	defer close(out.release)
+	l, err := NewWithSyncer(out, Config{Encoding: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SyncTimeout(10 * time.Millisecond); errors.Cause(err) != context.DeadlineExceeded {
+		t.Errorf("SyncTimeout = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestSyncContext(t *testing.T) {
+	out := &blockingSyncer{release: make(chan struct{})}
+	l, err := NewWithSyncer(out, Config{Encoding: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.SyncContext(ctx); errors.Cause(err) != context.Canceled {
+		t.Errorf("SyncContext = %v, want context.Canceled", err)
+	}
+
+	close(out.release)
+	if err := l.SyncContext(context.Background()); err != nil {
+		t.Errorf("SyncContext = %v after the sink was released", err)
+	}
+}
+
+func TestNewWithSyncerSyncError(t *testing.T) {
+	l, err := NewWithSyncer(failingSyncer{}, Config{Encoding: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Sync(); errors.Cause(err) != errOutput {
+		t.Errorf("Sync = %v, want the error of the syncer", err)
+	}
+	if paths := l.OutputPaths(); len(paths) != 0 {
+		t.Errorf("OutputPaths() = %v, want none", paths)
+	}
+}
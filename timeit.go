@@ -0,0 +1,77 @@
+package log
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TimeitOption configures Timeit.
+type TimeitOption func(*timeitConfig)
+
+type timeitConfig struct {
+	level    zapcore.Level
+	logStart bool
+}
+
+// TimeitLevel makes Timeit log at the level instead of DEBUG.
+func TimeitLevel(level zapcore.Level) TimeitOption {
+	return func(c *timeitConfig) {
+		c.level = level
+	}
+}
+
+// TimeitStart makes Timeit log also an "Operation started" message when it is called.
+func TimeitStart() TimeitOption {
+	return func(c *timeitConfig) {
+		c.logStart = true
+	}
+}
+
+// Timeit returns a function which logs an "Operation completed" message with the "operation" field set to name
+// and the time elapsed since Timeit was called in the "duration_ms" field, at DEBUG level unless TimeitLevel
+// is given. It is meant to be deferred:
+//
+//	defer logger.Timeit(ctx, "import")()
+func (l *logger) Timeit(ctx context.Context, name string, opts ...TimeitOption) func() {
+	conf := timeitConfig{level: zapcore.DebugLevel}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	logger := l.With(ctx, "operation", name)
+	if conf.logStart {
+		if ce := logger.skipCaller().Desugar().Check(conf.level, "Operation started"); ce != nil {
+			ce.Write()
+		}
+	}
+	start := time.Now()
+	return func() {
+		if ce := logger.skipCaller().Desugar().Check(conf.level, "Operation completed"); ce != nil {
+			ce.Write(zap.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000))
+		}
+	}
+}
+
+// TimeitThreshold returns a function like Timeit does, which logs an "Operation slow" message at WARN level with
+// the "operation", "duration_ms" and "threshold_ms" fields only if the time elapsed since TimeitThreshold was called
+// exceeds the threshold. The fast operations log nothing:
+//
+//	defer logger.TimeitThreshold(ctx, "import", time.Second)()
+func (l *logger) TimeitThreshold(ctx context.Context, name string, threshold time.Duration) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		if elapsed <= threshold {
+			return
+		}
+		if ce := l.With(ctx, "operation", name).skipCaller().Desugar().Check(zapcore.WarnLevel, "Operation slow"); ce != nil {
+			ce.Write(
+				zap.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+				zap.Float64("threshold_ms", float64(threshold.Microseconds())/1000),
+			)
+		}
+	}
+}
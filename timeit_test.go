@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestTimeit(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	done := l.Timeit(context.Background(), "import", TimeitLevel(zap.InfoLevel), TimeitStart())
+	time.Sleep(2 * time.Millisecond)
+	done()
+
+	entries := out.entries(t)
+	if len(entries) != 2 || entries[0]["message"] != "Operation started" || entries[1]["message"] != "Operation completed" {
+		t.Fatalf("entries = %v, want the start and the completion", entries)
+	}
+	end := entries[1]
+	if d, _ := end["duration_ms"].(float64); end["operation"] != "import" || d < 2 {
+		t.Errorf("completion = %v, want the operation and a duration of at least 2ms", end)
+	}
+	if caller, _ := end["caller"].(string); !strings.Contains(caller, "timeit_test.go") {
+		t.Errorf("caller = %q, want the line calling the returned function", caller)
+	}
+
+	out.Reset()
+	l.Timeit(context.Background(), "import")()
+	if out.String() != "" {
+		t.Errorf("Timeit logged at the disabled DEBUG level: %s", out.String())
+	}
+}
+
+func TestTimeitThreshold(t *testing.T) {
+	l, out := newBufferLogger(t, Config{})
+	l.TimeitThreshold(context.Background(), "import", time.Hour)()
+	if out.String() != "" {
+		t.Errorf("a fast operation logged: %s", out.String())
+	}
+
+	done := l.TimeitThreshold(context.Background(), "import", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	done()
+	entries := out.entries(t)
+	if len(entries) != 1 {
+		t.Fatalf("entries = %v, want one slow operation", entries)
+	}
+	e := entries[0]
+	if e["message"] != "Operation slow" || e["level"] != "warn" || e["operation"] != "import" {
+		t.Errorf("entry = %v, want a WARN slow operation", e)
+	}
+	if d, _ := e["duration_ms"].(float64); d < 2 || e["threshold_ms"] != float64(1) {
+		t.Errorf("entry = %v, want the duration and a 1ms threshold", e)
+	}
+}
@@ -0,0 +1,31 @@
+package log
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+const truncatedSuffix = "…[truncated]"
+
+// truncate cuts s to at most max bytes, without splitting a UTF-8 sequence, and marks it by truncatedSuffix.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max] + truncatedSuffix
+}
+
+// truncateCounting cuts s like truncate does, but marks it by the number of the bytes cut off.
+func truncateCounting(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…[truncated " + strconv.Itoa(len(s)-cut) + " bytes]"
+}
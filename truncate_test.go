@@ -0,0 +1,32 @@
+package log
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"too long", 3, "too…[truncated]"},
+		// "é" is 2 bytes, which are not split
+		{"café", 4, "caf…[truncated]"},
+	} {
+		if got := truncate(tt.s, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestMaxMessageBytes(t *testing.T) {
+	l, out := newBufferLogger(t, Config{MaxMessageBytes: 5})
+	l.Info("a long message")
+	l.Info("short")
+
+	entries := out.entries(t)
+	if entries[0]["message"] != "a lon…[truncated]" || entries[1]["message"] != "short" {
+		t.Errorf("messages = %q and %q", entries[0]["message"], entries[1]["message"])
+	}
+}